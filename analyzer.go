@@ -0,0 +1,165 @@
+package lintpack
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// AddAnalyzer registers a go/analysis analyzer as a new checker.
+//
+// The analyzer is executed once per package, along with all analyzers
+// it requires; its diagnostics are reported as the checker warnings.
+//
+// Empty info.Name is replaced by the analyzer name.
+// Empty info.Summary and info.Details are derived from the analyzer doc.
+func (coll *CheckerCollection) AddAnalyzer(info *CheckerInfo, a *analysis.Analyzer) {
+	if err := analysis.Validate([]*analysis.Analyzer{a}); err != nil {
+		panic(fmt.Sprintf("adding %s analyzer: %v", a.Name, err))
+	}
+	if info.Name == "" {
+		info.Name = a.Name
+	}
+	if info.Summary == "" {
+		info.Summary, info.Details = analyzerDoc(a.Doc, info.Details)
+	}
+	if info.FactTypes == nil {
+		info.FactTypes = analyzerFactTypes(a)
+	}
+	coll.AddPackageChecker(info, func(ctx *CheckerContext) PackageWalker {
+		return &analyzerWalker{ctx: ctx, analyzer: a}
+	})
+}
+
+// analyzerFactTypes returns fact types of the analyzer
+// and all analyzers it requires.
+func analyzerFactTypes(a *analysis.Analyzer) []Fact {
+	var list []Fact
+	seen := make(map[*analysis.Analyzer]bool)
+	var visit func(a *analysis.Analyzer)
+	visit = func(a *analysis.Analyzer) {
+		if seen[a] {
+			return
+		}
+		seen[a] = true
+		for _, fact := range a.FactTypes {
+			list = append(list, fact)
+		}
+		for _, req := range a.Requires {
+			visit(req)
+		}
+	}
+	visit(a)
+	return list
+}
+
+// analyzerDoc splits analyzer doc into summary and details.
+//
+// By convention, the first doc line is a summary sentence.
+func analyzerDoc(doc, details string) (string, string) {
+	doc = strings.TrimSpace(doc)
+	summary := doc
+	if i := strings.IndexByte(doc, '\n'); i != -1 {
+		summary = doc[:i]
+		if details == "" {
+			details = strings.TrimSpace(doc[i+1:])
+		}
+	}
+	summary = strings.TrimSuffix(strings.TrimSpace(summary), ".")
+	if r, size := utf8.DecodeRuneInString(summary); r != utf8.RuneError {
+		summary = string(unicode.ToUpper(r)) + summary[size:]
+	}
+	return summary, details
+}
+
+// analyzerWalker runs the analyzer over the package
+// and reports its diagnostics as warnings.
+type analyzerWalker struct {
+	ctx      *CheckerContext
+	analyzer *analysis.Analyzer
+}
+
+func (w *analyzerWalker) WalkPackage(files []*ast.File) {
+	r := newAnalysisRunner(w.ctx.Context, files)
+	r.exec(w.analyzer, func(d analysis.Diagnostic) {
+		w.ctx.Warn(posNode(d.Pos), "%s", d.Message)
+	})
+}
+
+// posNode is an ast.Node that only carries a position.
+type posNode token.Pos
+
+func (n posNode) Pos() token.Pos { return token.Pos(n) }
+func (n posNode) End() token.Pos { return token.Pos(n) }
+
+// analysisRunner is a minimal go/analysis driver for a single package.
+type analysisRunner struct {
+	ctx   *Context
+	files []*ast.File
+
+	results map[*analysis.Analyzer]interface{}
+}
+
+func newAnalysisRunner(ctx *Context, files []*ast.File) *analysisRunner {
+	return &analysisRunner{
+		ctx:     ctx,
+		files:   files,
+		results: make(map[*analysis.Analyzer]interface{}),
+	}
+}
+
+// exec runs the analyzer after all analyzers it requires.
+// Diagnostics are passed to the report function.
+//
+// Analyzer errors are reported with panic(error).
+func (r *analysisRunner) exec(a *analysis.Analyzer, report func(analysis.Diagnostic)) interface{} {
+	if result, ok := r.results[a]; ok {
+		return result
+	}
+
+	resultOf := make(map[*analysis.Analyzer]interface{}, len(a.Requires))
+	for _, req := range a.Requires {
+		// Diagnostics of the required analyzers are not reported.
+		resultOf[req] = r.exec(req, func(analysis.Diagnostic) {})
+	}
+
+	// Analyzer facts are stored along with checker facts.
+	// Names are prefixed to avoid clashes with checker names.
+	factsNamespace := "analysis/" + a.Name
+
+	pass := &analysis.Pass{
+		Analyzer:  a,
+		Fset:      r.ctx.FileSet,
+		Files:     r.files,
+		Pkg:       r.ctx.Pkg,
+		TypesInfo: r.ctx.TypesInfo,
+		ResultOf:  resultOf,
+		Report:    report,
+
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			return importObjectFact(r.ctx, factsNamespace, obj, fact)
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			exportObjectFact(r.ctx, factsNamespace, obj, fact)
+		},
+		ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+			return importPackageFact(r.ctx, factsNamespace, pkg, fact)
+		},
+		ExportPackageFact: func(fact analysis.Fact) {
+			exportPackageFact(r.ctx, factsNamespace, fact)
+		},
+	}
+
+	result, err := a.Run(pass)
+	if err != nil {
+		panic(fmt.Errorf("%s analyzer: %v", a.Name, err))
+	}
+	r.results[a] = result
+	return result
+}
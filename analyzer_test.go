@@ -0,0 +1,90 @@
+package lintpack
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+func TestAddAnalyzer(t *testing.T) {
+	emptyFunc := &analysis.Analyzer{
+		Name:     "emptyFunc",
+		Doc:      "report empty functions.\n\nEmpty functions are suspicious.",
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+			inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+				fn := n.(*ast.FuncDecl)
+				if len(fn.Body.List) == 0 {
+					pass.Reportf(fn.Pos(), "%s is empty", fn.Name.Name)
+				}
+			})
+			return nil, nil
+		},
+	}
+
+	coll := &CheckerCollection{URL: "example.com", Registry: NewRegistry()}
+	info := &CheckerInfo{Tags: []string{"experimental"}}
+	coll.AddAnalyzer(info, emptyFunc)
+
+	if info.Name != "emptyFunc" {
+		t.Errorf("name: have %q, want %q", info.Name, "emptyFunc")
+	}
+	if want := "Report empty functions"; info.Summary != want {
+		t.Errorf("summary: have %q, want %q", info.Summary, want)
+	}
+	if want := "Empty functions are suspicious."; info.Details != want {
+		t.Errorf("details: have %q, want %q", info.Details, want)
+	}
+
+	sources := map[string]string{
+		"a.go": "package example\nfunc f1() {}\nfunc f2() { f1() }\n",
+		"b.go": "package example\n\nfunc f3() {}\n",
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, filename := range []string{"a.go", "b.go"} {
+		f, err := parser.ParseFile(fset, filename, sources[filename], 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", filename, err)
+		}
+		files = append(files, f)
+	}
+	typesInfo := &types.Info{}
+	pkg, err := new(types.Config).Check("example", fset, files, typesInfo)
+	if err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+
+	ctx := NewContext(fset, types.SizesFor("gc", "amd64"))
+	ctx.SetPackageInfo(typesInfo, pkg)
+	ctx.SetPackageFiles(files)
+	c := coll.Registry.NewChecker(ctx, info)
+	for _, f := range files {
+		ctx.SetFileInfo("", f)
+		if warnings := c.Check(f); len(warnings) != 0 {
+			t.Errorf("unexpected file-level warnings: %v", warnings)
+		}
+	}
+
+	want := []string{
+		"a.go:2:1: f1 is empty",
+		"b.go:3:1: f3 is empty",
+	}
+	var have []string
+	for _, warn := range c.CheckPackage(files) {
+		pos := fset.Position(warn.Node.Pos())
+		have = append(have, fmt.Sprintf("%s: %s", pos, warn.Text))
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("warnings mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+}
@@ -0,0 +1,135 @@
+package astwalk
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// FuncCFG is a control-flow graph of a single function.
+type FuncCFG struct {
+	*cfg.CFG
+
+	// Func is either *ast.FuncDecl or *ast.FuncLit.
+	Func ast.Node
+
+	// Body is a function body the graph is built for.
+	Body *ast.BlockStmt
+
+	// blocks maps block nodes to their blocks.
+	blocks map[ast.Node]*cfg.Block
+}
+
+// BlockOf returns a block that contains n.
+//
+// n must be one of the block nodes: a simple statement,
+// a condition expression or a ValueSpec. Compound statements,
+// like if and for statements, are not block nodes;
+// their init statements and conditions are.
+// Returns nil if n is not found.
+func (g *FuncCFG) BlockOf(n ast.Node) *cfg.Block {
+	return g.blocks[n]
+}
+
+// IsReachable reports whether n is reachable from the function entry.
+// n must be a block node, see BlockOf.
+func (g *FuncCFG) IsReachable(n ast.Node) bool {
+	b := g.blocks[n]
+	return b != nil && b.Live
+}
+
+type funcCFGWalker struct {
+	visitor FuncCFGVisitor
+	info    *types.Info
+}
+
+func (w *funcCFGWalker) WalkFile(f *ast.File) {
+	if !w.visitor.EnterFile(f) {
+		return
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Body == nil || !w.visitor.EnterFunc(decl) {
+				continue
+			}
+			w.visitFunc(decl, decl.Body)
+			w.visitFuncLits(decl.Body)
+		case *ast.GenDecl:
+			// Package-level var initializers can contain function literals.
+			w.visitFuncLits(decl)
+		}
+	}
+}
+
+// visitFuncLits visits all function literals inside n, including nested ones.
+func (w *funcCFGWalker) visitFuncLits(n ast.Node) {
+	ast.Inspect(n, func(x ast.Node) bool {
+		if lit, ok := x.(*ast.FuncLit); ok {
+			w.visitFunc(lit, lit.Body)
+		}
+		return true
+	})
+}
+
+func (w *funcCFGWalker) visitFunc(fn ast.Node, body *ast.BlockStmt) {
+	g := &FuncCFG{
+		CFG:    cfg.New(body, w.mayReturn),
+		Func:   fn,
+		Body:   body,
+		blocks: make(map[ast.Node]*cfg.Block),
+	}
+	for _, b := range g.Blocks {
+		for _, n := range b.Nodes {
+			g.blocks[n] = b
+		}
+	}
+	w.visitor.VisitFuncCFG(g)
+}
+
+// mayReturn reports whether the call may return.
+// Calls that are known to never return are panic, os.Exit,
+// runtime.Goexit, log.Fatal-like and testing.FailNow-like calls.
+func (w *funcCFGWalker) mayReturn(call *ast.CallExpr) bool {
+	if id, ok := call.Fun.(*ast.Ident); ok {
+		if _, ok := w.info.Uses[id].(*types.Builtin); ok {
+			return id.Name != "panic"
+		}
+	}
+	fn := typeutil.StaticCallee(w.info, call)
+	if fn == nil {
+		return true
+	}
+	return !noReturnFuncs[fn.FullName()]
+}
+
+// noReturnFuncs is a set of functions that never return.
+// Keys are types.Func full names.
+var noReturnFuncs = map[string]bool{
+	"os.Exit":        true,
+	"runtime.Goexit": true,
+
+	"log.Fatal":   true,
+	"log.Fatalf":  true,
+	"log.Fatalln": true,
+	"log.Panic":   true,
+	"log.Panicf":  true,
+	"log.Panicln": true,
+
+	"(*log.Logger).Fatal":   true,
+	"(*log.Logger).Fatalf":  true,
+	"(*log.Logger).Fatalln": true,
+	"(*log.Logger).Panic":   true,
+	"(*log.Logger).Panicf":  true,
+	"(*log.Logger).Panicln": true,
+
+	"(*testing.common).FailNow": true,
+	"(*testing.common).Fatal":   true,
+	"(*testing.common).Fatalf":  true,
+	"(*testing.common).SkipNow": true,
+	"(*testing.common).Skip":    true,
+	"(*testing.common).Skipf":   true,
+}
@@ -0,0 +1,78 @@
+package astwalk
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+type funcCFGRecorder struct {
+	WalkHandler
+	fset    *token.FileSet
+	visited []string
+}
+
+func (v *funcCFGRecorder) EnterFunc(decl *ast.FuncDecl) bool {
+	// Doesn't check the body, the walker should skip bodyless functions.
+	return decl.Name.Name != "skipped"
+}
+
+func (v *funcCFGRecorder) VisitFuncCFG(g *FuncCFG) {
+	var name string
+	switch fn := g.Func.(type) {
+	case *ast.FuncDecl:
+		name = fn.Name.Name
+	case *ast.FuncLit:
+		name = fmt.Sprintf("lit:%d", v.fset.Position(fn.Pos()).Line)
+	}
+	v.visited = append(v.visited, name)
+}
+
+func TestFuncCFGWalker(t *testing.T) {
+	src := `package example
+
+var handler = func() {
+	_ = func() {}
+}
+
+var (
+	x = 1
+	y = func() int { return x }()
+)
+
+func f() {
+	g := func() {
+		_ = func() {}
+	}
+	g()
+}
+
+func bodyless()
+
+func skipped() {
+	_ = func() {}
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "example.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+	if _, err := new(types.Config).Check("example", fset, []*ast.File{f}, info); err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+
+	v := &funcCFGRecorder{fset: fset}
+	WalkerForFuncCFG(v, info).WalkFile(f)
+
+	have := strings.Join(v.visited, " ")
+	want := "lit:3 lit:4 lit:9 f lit:13 lit:14"
+	if have != want {
+		t.Errorf("visited functions mismatch:\nhave: %s\nwant: %s", have, want)
+	}
+}
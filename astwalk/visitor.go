@@ -64,6 +64,16 @@ type (
 		walkerEvents
 		VisitComment(*ast.CommentGroup)
 	}
+
+	// FuncCFGVisitor visits control-flow graph of every function.
+	// Function literals are visited after the enclosing function
+	// declaration; they are skipped if EnterFunc returned false.
+	// Function literals of package-level declarations are always visited.
+	// Functions without body are never visited.
+	FuncCFGVisitor interface {
+		walkerEvents
+		VisitFuncCFG(*FuncCFG)
+	}
 )
 
 // walkerEvents describes common hooks available for most visitor types.
@@ -55,3 +55,9 @@ func WalkerForDocComment(v DocCommentVisitor) lintpack.FileWalker {
 func WalkerForLocalDef(v LocalDefVisitor, info *types.Info) lintpack.FileWalker {
 	return &localDefWalker{visitor: v, info: info}
 }
+
+// WalkerForFuncCFG returns file walker implementation for FuncCFGVisitor.
+// info is used to detect calls that never return, like panic and os.Exit.
+func WalkerForFuncCFG(v FuncCFGVisitor, info *types.Info) lintpack.FileWalker {
+	return &funcCFGWalker{visitor: v, info: info}
+}
@@ -29,7 +29,7 @@ func init() {
 
 	collection.AddChecker(&info, func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
 		c := &panicNilChecker{ctx: ctx}
-		c.skipNilEfaceLit = info.Params.Bool("skipNilEfaceLit")
+		c.skipNilEfaceLit = ctx.Params.Bool("skipNilEfaceLit")
 		return astwalk.WalkerForExpr(c)
 	})
 }
@@ -1,27 +1,34 @@
 package lintpack
 
 import (
+	"errors"
 	"fmt"
+	"go/parser"
+	"go/token"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-toolsmith/astfmt"
 )
 
 type checkerProto struct {
 	info        *CheckerInfo
-	constructor func(*Context) *Checker
+	constructor func(*Context, CheckerParams) *Checker
 }
 
-// prototypes is a set of registered checkers that are not yet instantiated.
-// Registration should be done with AddChecker function.
-// Initialized checkers can be obtained with NewChecker function.
-var prototypes = make(map[string]checkerProto)
+// defaultRegistry is a registry used by the package-level functions
+// and by the collections that have no explicit registry.
+var defaultRegistry = NewRegistry()
 
-func getCheckersInfo() []*CheckerInfo {
-	infoList := make([]*CheckerInfo, 0, len(prototypes))
-	for _, proto := range prototypes {
+func (r *Registry) getCheckersInfo() []*CheckerInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	infoList := make([]*CheckerInfo, 0, len(r.prototypes))
+	for _, proto := range r.prototypes {
 		infoCopy := *proto.info
 		infoList = append(infoList, &infoCopy)
 	}
@@ -31,19 +38,18 @@ func getCheckersInfo() []*CheckerInfo {
 	return infoList
 }
 
-func addChecker(info *CheckerInfo, constructor func(*CheckerContext) FileWalker) {
-	if _, ok := prototypes[info.Name]; ok {
-		panic(fmt.Sprintf("checker with name %q already registered", info.Name))
+func (r *Registry) addChecker(info *CheckerInfo, constructor func(*CheckerContext) (FileWalker, PackageWalker)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.prototypes[info.Name]; ok {
+		return fmt.Errorf("checker with name %q already registered", info.Name)
 	}
 
-	// Validate param value type.
+	// Validate param value types and constraints.
 	for pname, param := range info.Params {
-		switch param.Value.(type) {
-		case string, int, bool:
-			// OK.
-		default:
-			panic(fmt.Sprintf("unsupported %q param type value: %T",
-				pname, param.Value))
+		if err := param.Validate(); err != nil {
+			return fmt.Errorf("%s: invalid %q param: %v", info.Name, pname, err)
 		}
 	}
 
@@ -62,40 +68,73 @@ func addChecker(info *CheckerInfo, constructor func(*CheckerContext) FileWalker)
 
 	trimDocumentation(info)
 
+	if info.Severity == 0 {
+		info.Severity = SeverityWarning
+	}
+
 	if err := validateCheckerInfo(info); err != nil {
-		panic(err)
+		return err
 	}
 
 	proto := checkerProto{
 		info: info,
-		constructor: func(ctx *Context) *Checker {
+		constructor: func(ctx *Context, params CheckerParams) *Checker {
 			var c Checker
 			c.Info = info
 			c.ctx = CheckerContext{
 				Context: ctx,
+				Params:  params,
+				info:    info,
 				printer: astfmt.NewPrinter(ctx.FileSet),
 			}
-			c.fileWalker = constructor(&c.ctx)
+			c.fileWalker, c.packageWalker = constructor(&c.ctx)
 			return &c
 		},
 	}
 
-	prototypes[info.Name] = proto
+	r.prototypes[info.Name] = proto
+	return nil
 }
 
-func newChecker(ctx *Context, info *CheckerInfo) *Checker {
-	proto, ok := prototypes[info.Name]
+func (r *Registry) newChecker(ctx *Context, info *CheckerInfo, values map[string]interface{}) *Checker {
+	r.mu.RLock()
+	proto, ok := r.prototypes[info.Name]
+	r.mu.RUnlock()
 	if !ok {
 		panic(fmt.Sprintf("checker with name %q not registered", info.Name))
 	}
-	return proto.constructor(ctx)
+	if len(values) == 0 {
+		return proto.constructor(ctx, info.Params)
+	}
+
+	params := make(CheckerParams, len(info.Params))
+	for pname, param := range info.Params {
+		params[pname] = param
+	}
+	for pname, v := range values {
+		param := info.Params[pname]
+		if param == nil {
+			panic(fmt.Sprintf("%s: unknown %q param", info.Name, pname))
+		}
+		if err := param.checkValue(v); err != nil {
+			panic(fmt.Sprintf("%s: %q param: %v", info.Name, pname, err))
+		}
+		p := *param
+		p.Value = v
+		params[pname] = &p
+	}
+	return proto.constructor(ctx, params)
 }
 
 func validateCheckerInfo(info *CheckerInfo) error {
 	steps := []func(*CheckerInfo) error{
 		validateCheckerName,
+		validateCheckerCollection,
 		validateCheckerDocumentation,
 		validateCheckerTags,
+		validateCheckerSeverity,
+		validateCheckerResources,
+		validateCheckerFactTypes,
 	}
 
 	for _, step := range steps {
@@ -115,8 +154,76 @@ func validateCheckerName(info *CheckerInfo) error {
 	return nil
 }
 
+func validateCheckerCollection(info *CheckerInfo) error {
+	if info.Collection == nil {
+		return errors.New("missing collection")
+	}
+	return nil
+}
+
 func validateCheckerDocumentation(info *CheckerInfo) error {
-	// TODO(Quasilyte): validate documentation.
+	switch {
+	case info.Summary == "":
+		return errors.New("missing summary")
+	case strings.HasSuffix(info.Summary, "."):
+		return errors.New("summary should not end with a period")
+	case strings.Contains(info.Summary, "\n"):
+		return errors.New("summary should be a single line")
+	case info.Before != "" && info.After == "":
+		return errors.New("missing After snippet")
+	case info.Before == "" && info.After != "":
+		return errors.New("missing Before snippet")
+	}
+
+	for pname, param := range info.Params {
+		if strings.TrimSpace(param.Usage) == "" {
+			return fmt.Errorf("missing %q param usage", pname)
+		}
+	}
+
+	if info.Before != "" {
+		if err := validateCodeSnippet(info.Before); err != nil {
+			return fmt.Errorf("parse Before snippet: %v", err)
+		}
+		if err := validateCodeSnippet(info.After); err != nil {
+			return fmt.Errorf("parse After snippet: %v", err)
+		}
+	}
+
+	if info.Collection != nil && info.Collection.StrictDocumentation {
+		return validateCheckerDocumentationStrict(info)
+	}
+	return nil
+}
+
+func validateCheckerDocumentationStrict(info *CheckerInfo) error {
+	if r, _ := utf8.DecodeRuneInString(info.Summary); !unicode.IsUpper(r) {
+		return errors.New("summary should start with a capital letter")
+	}
+	if info.Before == "" {
+		return errors.New("missing Before and After snippets")
+	}
+	if info.Before == info.After {
+		return errors.New("Before and After snippets are identical")
+	}
+	return nil
+}
+
+// validateCodeSnippet checks that src is a non-empty list of Go statements
+// or a non-empty list of Go declarations.
+func validateCodeSnippet(src string) error {
+	if strings.TrimSpace(src) == "" {
+		return errors.New("empty snippet")
+	}
+	fset := token.NewFileSet()
+	stmtsSrc := "package p; func _() {\n" + src + "\n}"
+	if _, err := parser.ParseFile(fset, "", stmtsSrc, 0); err == nil {
+		return nil
+	}
+	declsSrc := "package p\n" + src
+	if _, err := parser.ParseFile(fset, "", declsSrc, 0); err != nil {
+		return fmt.Errorf("neither statements nor declarations: %v", err)
+	}
 	return nil
 }
 
@@ -133,3 +240,40 @@ func validateCheckerTags(info *CheckerInfo) error {
 	}
 	return nil
 }
+
+func validateCheckerSeverity(info *CheckerInfo) error {
+	if !info.Severity.IsValid() {
+		return fmt.Errorf("invalid severity: %v", info.Severity)
+	}
+	return nil
+}
+
+func validateCheckerFactTypes(info *CheckerInfo) error {
+	for _, fact := range info.FactTypes {
+		if fact == nil {
+			return errors.New("nil fact type")
+		}
+		if typ := reflect.TypeOf(fact); typ.Kind() != reflect.Ptr {
+			return fmt.Errorf("fact type %s is not a pointer", typ)
+		}
+	}
+	return nil
+}
+
+func validateCheckerResources(info *CheckerInfo) error {
+	resourceSet := make(map[*Resource]bool)
+	for _, r := range info.Resources {
+		switch {
+		case r == nil:
+			return errors.New("nil resource")
+		case r.Compute == nil:
+			return fmt.Errorf("resource %q has no Compute func", r.Name)
+		case r.Scope != FileScope && r.Scope != PackageScope:
+			return fmt.Errorf("resource %q has invalid scope", r.Name)
+		case resourceSet[r]:
+			return fmt.Errorf("duplicated resource %q", r.Name)
+		}
+		resourceSet[r] = true
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package lintpack
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestValidateCheckerDocumentation(t *testing.T) {
+	strict := &CheckerCollection{StrictDocumentation: true}
+
+	tests := []struct {
+		info *CheckerInfo
+		err  string
+	}{
+		{
+			info: &CheckerInfo{Summary: "Detects something"},
+		},
+		{
+			info: &CheckerInfo{Summary: "Detects something."},
+			err:  "summary should not end with a period",
+		},
+		{
+			info: &CheckerInfo{},
+			err:  "missing summary",
+		},
+		{
+			info: &CheckerInfo{Summary: "Detects\nsomething"},
+			err:  "summary should be a single line",
+		},
+		{
+			info: &CheckerInfo{Summary: "Detects something", Before: `f(x)`},
+			err:  "missing After snippet",
+		},
+		{
+			info: &CheckerInfo{
+				Summary: "Detects something",
+				Params: CheckerParams{
+					"limit": {Value: 10},
+				},
+			},
+			err: `missing "limit" param usage`,
+		},
+		{
+			info: &CheckerInfo{
+				Summary:    "Detects something",
+				Before:     "x := f(\n",
+				After:      "x := g()",
+				Collection: &CheckerCollection{},
+			},
+			err: "parse Before snippet",
+		},
+		{
+			info: &CheckerInfo{
+				Summary: "Detects something",
+				Before:  "f()",
+				After:   "x :=",
+			},
+			err: "parse After snippet",
+		},
+		{
+			info: &CheckerInfo{
+				Summary: "Detects something",
+				Before:  "f()",
+				After:   " \n\t",
+			},
+			err: "parse After snippet: empty snippet",
+		},
+		{
+			info: &CheckerInfo{
+				Summary: "detects something",
+				Before:  "f()",
+				After:   "f()",
+			},
+		},
+
+		{
+			info: &CheckerInfo{
+				Summary:    "Detects something",
+				Before:     "x := f()\n_ = x",
+				After:      "type T struct{}\n\nfunc (T) f() {}",
+				Collection: strict,
+			},
+		},
+		{
+			info: &CheckerInfo{
+				Summary:    "detects something",
+				Before:     "f()",
+				After:      "g()",
+				Collection: strict,
+			},
+			err: "summary should start with a capital letter",
+		},
+		{
+			info: &CheckerInfo{
+				Summary:    "Detects something",
+				Collection: strict,
+			},
+			err: "missing Before and After snippets",
+		},
+		{
+			info: &CheckerInfo{
+				Summary:    "Detects something",
+				Before:     "f()",
+				After:      "f()",
+				Collection: strict,
+			},
+			err: "Before and After snippets are identical",
+		},
+		{
+			info: &CheckerInfo{
+				Summary:    "Detects something",
+				Before:     "x := f(\n",
+				After:      "x := g()",
+				Collection: strict,
+			},
+			err: "parse Before snippet",
+		},
+	}
+
+	for i, test := range tests {
+		err := validateCheckerDocumentation(test.info)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		case test.err != "" && err == nil:
+			t.Errorf("test %d: expected %q error", i, test.err)
+		case err != nil && !strings.Contains(err.Error(), test.err):
+			t.Errorf("test %d: error mismatch:\nhave: %v\nwant: %s", i, err, test.err)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	newWalker := func(ctx *CheckerContext) FileWalker { return nil }
+
+	r1 := NewRegistry()
+	r2 := NewRegistry()
+	coll := &CheckerCollection{URL: "example.com"}
+	info1 := &CheckerInfo{Name: "example", Summary: "Example", Collection: coll}
+	info2 := &CheckerInfo{Name: "example", Summary: "Example", Collection: coll}
+
+	if err := r1.AddChecker(info1, newWalker); err != nil {
+		t.Fatalf("r1: unexpected error: %v", err)
+	}
+	if err := r2.AddChecker(info2, newWalker); err != nil {
+		t.Fatalf("r2: unexpected error: %v", err)
+	}
+	if err := r1.AddChecker(info2, newWalker); err == nil {
+		t.Errorf("r1: expected duplicated name error")
+	}
+	if err := r1.AddChecker(&CheckerInfo{Name: "bad name", Collection: coll}, newWalker); err == nil {
+		t.Errorf("r1: expected invalid info error")
+	}
+	noColl := &CheckerInfo{Name: "noColl", Summary: "Example"}
+	if err := r1.AddChecker(noColl, newWalker); err == nil || !strings.Contains(err.Error(), "missing collection") {
+		t.Errorf("r1: expected missing collection error, have %v", err)
+	}
+	newPackageWalker := func(ctx *CheckerContext) PackageWalker { return nil }
+	if err := r1.AddPackageChecker(noColl, newPackageWalker); err == nil || !strings.Contains(err.Error(), "missing collection") {
+		t.Errorf("r1: expected missing package checker collection error, have %v", err)
+	}
+
+	if n := len(r1.GetCheckersInfo()); n != 1 {
+		t.Errorf("r1: have %d checkers, want 1", n)
+	}
+	for _, info := range GetCheckersInfo() {
+		if info.Name == "example" {
+			t.Errorf("default registry: unexpected %q checker", info.Name)
+		}
+	}
+}
+
+type paramsWalker struct {
+	ctx *CheckerContext
+}
+
+func (w *paramsWalker) WalkFile(f *ast.File) {
+	w.ctx.Warn(f, "limit=%d", w.ctx.Params.Int("limit"))
+}
+
+func TestNewCheckerWithParams(t *testing.T) {
+	coll := &CheckerCollection{URL: "example.com", Registry: NewRegistry()}
+	info := &CheckerInfo{
+		Name:    "params",
+		Summary: "Reports the limit",
+		Params: CheckerParams{
+			"limit": {Value: 10, Max: 100, Usage: "example limit"},
+			"names": {Value: []string{"a"}, Usage: "example names"},
+		},
+	}
+	coll.AddChecker(info, func(ctx *CheckerContext) FileWalker {
+		return &paramsWalker{ctx: ctx}
+	})
+
+	ctx := NewContext(token.NewFileSet(), nil)
+	f := &ast.File{Name: ast.NewIdent("example")}
+	base := coll.Registry.NewChecker(ctx, info)
+	overridden := coll.Registry.NewCheckerWithParams(ctx, info, map[string]interface{}{"limit": 20})
+
+	for _, test := range []struct {
+		c    *Checker
+		want string
+	}{
+		{base, "limit=10"},
+		{overridden, "limit=20"},
+		{base, "limit=10"},
+	} {
+		warns := test.c.Check(f)
+		if len(warns) != 1 || warns[0].Text != test.want {
+			t.Errorf("have %+v, want %q warning", warns, test.want)
+		}
+	}
+	if info.Params.Int("limit") != 10 {
+		t.Errorf("info param value is changed to %v", info.Params["limit"].Value)
+	}
+	if have := overridden.Params()["names"]; have != info.Params["names"] {
+		t.Errorf("not overridden param is copied: %+v", have)
+	}
+
+	for _, values := range []map[string]interface{}{
+		{"unknown": 1},
+		{"limit": "20"},
+		{"limit": 200},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v: expected a panic", values)
+				}
+			}()
+			coll.Registry.NewCheckerWithParams(ctx, info, values)
+		}()
+	}
+}
@@ -0,0 +1,224 @@
+package lintpack
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// Fact is a serializable piece of information about an object or a package.
+//
+// Facts exported while checking a package can be imported while
+// checking the packages that depend on it. Facts are private to the
+// checker that exported them.
+//
+// Fact types must be pointers to types that can be encoded by encoding/gob.
+// The AFact method is a marker; it's named after go/analysis Fact
+// method, so the same fact types can be used by analyzers.
+type Fact interface {
+	AFact()
+}
+
+// factKey identifies a single fact inside a package.
+type factKey struct {
+	// checker is a name of the checker that exported the fact.
+	checker string
+
+	// object is an object path inside the package.
+	// Empty for package facts.
+	object objectpath.Path
+
+	// typ is a fact type name.
+	typ string
+}
+
+// factStore holds encoded facts for all checked packages.
+//
+// Facts are keyed by package paths, so they can be shared between
+// packages that were type-checked separately.
+type factStore struct {
+	mu       sync.Mutex
+	packages map[string]map[factKey][]byte
+}
+
+func (s *factStore) get(pkgPath string, key factKey) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.packages[pkgPath][key]
+	return data, ok
+}
+
+func (s *factStore) put(pkgPath string, key factKey, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.packages == nil {
+		s.packages = make(map[string]map[factKey][]byte)
+	}
+	facts := s.packages[pkgPath]
+	if facts == nil {
+		facts = make(map[factKey][]byte)
+		s.packages[pkgPath] = facts
+	}
+	facts[key] = data
+}
+
+func factTypeName(fact Fact) string {
+	typ := reflect.TypeOf(fact)
+	if typ.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("fact type %s is not a pointer", typ))
+	}
+	return typ.Elem().PkgPath() + "." + typ.Elem().Name()
+}
+
+func objectFactKey(checker string, obj types.Object, fact Fact) factKey {
+	path, err := objectpath.For(obj)
+	if err != nil {
+		panic(fmt.Sprintf("%s: can't use %v object for facts: %v", checker, obj, err))
+	}
+	return factKey{checker: checker, object: path, typ: factTypeName(fact)}
+}
+
+func encodeFact(fact Fact) []byte {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(fact); err != nil {
+		panic(fmt.Errorf("encoding %T fact: %v", fact, err))
+	}
+	return buf.Bytes()
+}
+
+func decodeFact(data []byte, fact Fact) {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(fact); err != nil {
+		panic(fmt.Errorf("decoding %T fact: %v", fact, err))
+	}
+}
+
+// ExportObjectFact associates a fact with the object.
+// The object must belong to the package being checked and must be
+// reachable from the package scope (package-level objects, methods
+// and fields of package-level types).
+func (ctx *CheckerContext) ExportObjectFact(obj types.Object, fact Fact) {
+	exportObjectFact(ctx.Context, ctx.info.Name, obj, fact)
+}
+
+// ImportObjectFact retrieves a fact associated with the object
+// by the same checker and stores it into the fact pointer.
+// Reports whether the fact was found.
+func (ctx *CheckerContext) ImportObjectFact(obj types.Object, fact Fact) bool {
+	return importObjectFact(ctx.Context, ctx.info.Name, obj, fact)
+}
+
+// ExportPackageFact associates a fact with the package being checked.
+func (ctx *CheckerContext) ExportPackageFact(fact Fact) {
+	exportPackageFact(ctx.Context, ctx.info.Name, fact)
+}
+
+// ImportPackageFact retrieves a fact associated with the package
+// by the same checker and stores it into the fact pointer.
+// Reports whether the fact was found.
+func (ctx *CheckerContext) ImportPackageFact(pkg *types.Package, fact Fact) bool {
+	return importPackageFact(ctx.Context, ctx.info.Name, pkg, fact)
+}
+
+func exportObjectFact(ctx *Context, checker string, obj types.Object, fact Fact) {
+	if obj.Pkg() != ctx.Pkg {
+		panic(fmt.Sprintf("%s: exporting fact for %v object of another package", checker, obj))
+	}
+	key := objectFactKey(checker, obj, fact)
+	ctx.facts.put(ctx.Pkg.Path(), key, encodeFact(fact))
+}
+
+func importObjectFact(ctx *Context, checker string, obj types.Object, fact Fact) bool {
+	if obj.Pkg() == nil {
+		return false // Universe scope objects have no facts
+	}
+	path, err := objectpath.For(obj)
+	if err != nil {
+		return false // Local objects can't have facts
+	}
+	key := factKey{checker: checker, object: path, typ: factTypeName(fact)}
+	data, ok := ctx.facts.get(obj.Pkg().Path(), key)
+	if ok {
+		decodeFact(data, fact)
+	}
+	return ok
+}
+
+func exportPackageFact(ctx *Context, checker string, fact Fact) {
+	key := factKey{checker: checker, typ: factTypeName(fact)}
+	ctx.facts.put(ctx.Pkg.Path(), key, encodeFact(fact))
+}
+
+func importPackageFact(ctx *Context, checker string, pkg *types.Package, fact Fact) bool {
+	key := factKey{checker: checker, typ: factTypeName(fact)}
+	data, ok := ctx.facts.get(pkg.Path(), key)
+	if ok {
+		decodeFact(data, fact)
+	}
+	return ok
+}
+
+// EncodedFact is a fact in its serialized form.
+//
+// Encoded facts are used to save facts of the checked package
+// and restore them later without checking the package again.
+type EncodedFact struct {
+	// Checker is a name of the checker that exported the fact.
+	Checker string
+
+	// Object is an object path inside the package.
+	// Empty for package facts.
+	Object string
+
+	// Type is a fact type name.
+	Type string
+
+	// Data is a gob-encoded fact value.
+	Data []byte
+}
+
+// PackageFacts returns all facts exported for the package
+// with the given path, sorted by their checker, object and type.
+func (c *Context) PackageFacts(pkgPath string) []EncodedFact {
+	c.facts.mu.Lock()
+	defer c.facts.mu.Unlock()
+	facts := c.facts.packages[pkgPath]
+	list := make([]EncodedFact, 0, len(facts))
+	for key, data := range facts {
+		list = append(list, EncodedFact{
+			Checker: key.checker,
+			Object:  string(key.object),
+			Type:    key.typ,
+			Data:    data,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		x, y := list[i], list[j]
+		if x.Checker != y.Checker {
+			return x.Checker < y.Checker
+		}
+		if x.Object != y.Object {
+			return x.Object < y.Object
+		}
+		return x.Type < y.Type
+	})
+	return list
+}
+
+// AddPackageFacts adds facts returned by PackageFacts
+// to the facts of the package with the given path.
+func (c *Context) AddPackageFacts(pkgPath string, facts []EncodedFact) {
+	for _, fact := range facts {
+		key := factKey{
+			checker: fact.Checker,
+			object:  objectpath.Path(fact.Object),
+			typ:     fact.Type,
+		}
+		c.facts.put(pkgPath, key, fact.Data)
+	}
+}
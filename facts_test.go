@@ -0,0 +1,152 @@
+package lintpack
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+type emptyFuncFact struct {
+	Name string
+}
+
+func (*emptyFuncFact) AFact() {}
+
+// emptyCallWalker exports facts for empty functions and
+// warns about calls to empty functions of other packages.
+type emptyCallWalker struct {
+	ctx *CheckerContext
+}
+
+func (w *emptyCallWalker) WalkFile(f *ast.File) {
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && len(fn.Body.List) == 0 {
+			obj := w.ctx.TypesInfo.ObjectOf(fn.Name)
+			w.ctx.ExportObjectFact(obj, &emptyFuncFact{Name: fn.Name.Name})
+		}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		var fact emptyFuncFact
+		if w.ctx.ImportObjectFact(w.ctx.TypesInfo.ObjectOf(sel.Sel), &fact) {
+			w.ctx.Warn(sel, "%s is empty", fact.Name)
+		}
+		return true
+	})
+}
+
+func TestFacts(t *testing.T) {
+	coll := &CheckerCollection{URL: "example.com", Registry: NewRegistry()}
+	info := &CheckerInfo{
+		Name:    "emptyCall",
+		Summary: "Detects calls to empty functions",
+	}
+	coll.AddChecker(info, func(ctx *CheckerContext) FileWalker {
+		return &emptyCallWalker{ctx: ctx}
+	})
+
+	sources := map[string]string{
+		"example.com/b": "package b\nfunc Empty() {}\nfunc NonEmpty() { Empty() }\n",
+		"example.com/a": "package a\nimport \"example.com/b\"\nfunc f() { b.Empty(); b.NonEmpty() }\n",
+	}
+
+	fset := token.NewFileSet()
+	// typecheck parses and type-checks a package from sources.
+	// Every call creates new package objects, as if the package
+	// was loaded separately from export data.
+	var typecheck func(path string, info *types.Info) (*types.Package, *ast.File)
+	typecheck = func(path string, info *types.Info) (*types.Package, *ast.File) {
+		f, err := parser.ParseFile(fset, path+".go", sources[path], 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", path, err)
+		}
+		cfg := types.Config{
+			Importer: importerFunc(func(path string) (*types.Package, error) {
+				pkg, _ := typecheck(path, &types.Info{})
+				return pkg, nil
+			}),
+		}
+		pkg, err := cfg.Check(path, fset, []*ast.File{f}, info)
+		if err != nil {
+			t.Fatalf("typecheck %s: %v", path, err)
+		}
+		return pkg, f
+	}
+
+	// check runs the checker over the packages in the given order.
+	check := func(ctx *Context, paths ...string) []string {
+		c := coll.Registry.NewChecker(ctx, info)
+		var warnings []string
+		for _, path := range paths {
+			typesInfo := &types.Info{
+				Defs: make(map[*ast.Ident]types.Object),
+				Uses: make(map[*ast.Ident]types.Object),
+			}
+			pkg, f := typecheck(path, typesInfo)
+			ctx.SetPackageInfo(typesInfo, pkg)
+			ctx.SetPackageFiles([]*ast.File{f})
+			ctx.SetFileInfo(path+".go", f)
+			for _, warn := range c.Check(f) {
+				warnings = append(warnings, fset.Position(warn.Node.Pos()).String()+": "+warn.Text)
+			}
+		}
+		return warnings
+	}
+
+	want := "example.com/a.go:3:12: Empty is empty"
+
+	ctx := NewContext(fset, types.SizesFor("gc", "amd64"))
+	warnings := check(ctx, "example.com/b", "example.com/a")
+	if len(warnings) != 1 || warnings[0] != want {
+		t.Errorf("warnings mismatch:\nhave: %q\nwant: %q", warnings, want)
+	}
+
+	// Restored facts are used as if b was checked by the same context.
+	facts := ctx.PackageFacts("example.com/b")
+	if len(facts) != 1 {
+		t.Fatalf("have %d facts for b, want 1", len(facts))
+	}
+	restored := NewContext(fset, types.SizesFor("gc", "amd64"))
+	restored.AddPackageFacts("example.com/b", facts)
+	warnings = check(restored, "example.com/a")
+	if len(warnings) != 1 || warnings[0] != want {
+		t.Errorf("warnings mismatch with restored facts:\nhave: %q\nwant: %q", warnings, want)
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// valueFact is a fact with a non-pointer receiver.
+type valueFact struct{}
+
+func (valueFact) AFact() {}
+
+func TestValidateCheckerFactTypes(t *testing.T) {
+	tests := []struct {
+		factTypes []Fact
+		err       string
+	}{
+		{factTypes: []Fact{new(emptyFuncFact)}},
+		{factTypes: []Fact{nil}, err: "nil fact type"},
+		{factTypes: []Fact{valueFact{}}, err: "is not a pointer"},
+	}
+	for _, test := range tests {
+		err := validateCheckerFactTypes(&CheckerInfo{FactTypes: test.factTypes})
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%v: unexpected error: %v", test.factTypes, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%v: have %v, want %q error", test.factTypes, err, test.err)
+		}
+	}
+}
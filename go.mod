@@ -9,6 +9,7 @@ require (
 	github.com/google/go-cmp v0.2.0
 	github.com/logrusorgru/aurora v0.0.0-20181002194514-a7b3b318ed4e
 	golang.org/x/tools v0.0.0-20181117154741-2ddaf7f79a09
+	gopkg.in/yaml.v2 v2.2.2
 )
 
 go 1.13
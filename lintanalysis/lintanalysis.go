@@ -0,0 +1,141 @@
+// Package lintanalysis exports lintpack checkers as go/analysis analyzers.
+//
+// This makes it possible to run lintpack checkers with any driver that
+// speaks golang.org/x/tools/go/analysis, like multichecker:
+//
+//	import (
+//		"github.com/go-lintpack/lintpack/lintanalysis"
+//		"golang.org/x/tools/go/analysis/multichecker"
+//
+//		_ "github.com/go-critic/go-critic/checkers"
+//	)
+//
+//	func main() {
+//		multichecker.Main(lintanalysis.Analyzers()...)
+//	}
+//
+// Checker parameters are exposed as analyzer flags.
+// Warning severity is reported as a diagnostic category.
+//
+// Checkers that declare CheckerInfo.FactTypes pass their facts
+// to the dependent packages through the analysis facts.
+package lintanalysis
+
+import (
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"runtime"
+
+	"github.com/go-lintpack/lintpack"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzers returns analyzers for all registered checkers.
+// The slice is sorted by a checker name.
+func Analyzers() []*analysis.Analyzer {
+	var list []*analysis.Analyzer
+	for _, info := range lintpack.GetCheckersInfo() {
+		list = append(list, NewAnalyzer(info))
+	}
+	return list
+}
+
+// NewAnalyzer returns an analyzer that runs checker described by info.
+// info must describe a registered checker.
+func NewAnalyzer(info *lintpack.CheckerInfo) *analysis.Analyzer {
+	a := &analysis.Analyzer{
+		Name: info.Name,
+		Doc:  analyzerDoc(info),
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			return nil, run(pass, info)
+		},
+	}
+	for pname, param := range info.Params {
+		a.Flags.Var(param, pname, param.Usage)
+	}
+	if len(info.FactTypes) != 0 {
+		a.FactTypes = []analysis.Fact{new(packageFacts)}
+	}
+	return a
+}
+
+// packageFacts is an analysis package fact that holds
+// all facts the checker exported for the package.
+//
+// Checker facts are keyed by their object paths, so they are bridged
+// as a single package fact, rather than as separate object facts.
+type packageFacts struct {
+	Facts []lintpack.EncodedFact
+}
+
+func (*packageFacts) AFact() {}
+
+func analyzerDoc(info *lintpack.CheckerInfo) string {
+	doc := info.Summary
+	if info.Details != "" {
+		doc += "\n\n" + info.Details
+	}
+	return doc
+}
+
+func run(pass *analysis.Pass, info *lintpack.CheckerInfo) error {
+	sizes := types.SizesFor("gc", runtime.GOARCH)
+	if sizes == nil {
+		return fmt.Errorf("can't find sizes info for %s", runtime.GOARCH)
+	}
+
+	ctx := lintpack.NewContext(pass.Fset, sizes)
+	if len(info.FactTypes) != 0 {
+		importFacts(pass, ctx)
+	}
+	ctx.SetPackageInfo(pass.TypesInfo, pass.Pkg)
+	ctx.SetPackageFiles(pass.Files)
+	c := lintpack.NewChecker(ctx, info)
+	for _, f := range pass.Files {
+		// See https://github.com/golang/go/issues/24498.
+		filename := filepath.Base(pass.Fset.Position(f.Pos()).Filename)
+		ctx.SetFileInfo(filename, f)
+		report(pass, c.Check(f))
+	}
+	report(pass, c.CheckPackage(pass.Files))
+	if len(info.FactTypes) != 0 {
+		if facts := ctx.PackageFacts(pass.Pkg.Path()); len(facts) != 0 {
+			pass.ExportPackageFact(&packageFacts{Facts: facts})
+		}
+	}
+	return nil
+}
+
+// importFacts adds the checker facts of all packages
+// the checked package depends on to the ctx.
+func importFacts(pass *analysis.Pass, ctx *lintpack.Context) {
+	visited := make(map[*types.Package]bool)
+	var visit func(pkg *types.Package)
+	visit = func(pkg *types.Package) {
+		if visited[pkg] {
+			return
+		}
+		visited[pkg] = true
+		var facts packageFacts
+		if pass.ImportPackageFact(pkg, &facts) {
+			ctx.AddPackageFacts(pkg.Path(), facts.Facts)
+		}
+		for _, dep := range pkg.Imports() {
+			visit(dep)
+		}
+	}
+	for _, dep := range pass.Pkg.Imports() {
+		visit(dep)
+	}
+}
+
+func report(pass *analysis.Pass, warnings []lintpack.Warning) {
+	for _, warn := range warnings {
+		pass.Report(analysis.Diagnostic{
+			Pos:      warn.Node.Pos(),
+			Category: warn.Severity.String(),
+			Message:  warn.Text,
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package lintanalysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+	_ "github.com/go-lintpack/lintpack/checkers"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestAnalyzers(t *testing.T) {
+	analyzers := Analyzers()
+	if err := analysis.Validate(analyzers); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	var panicNil *analysis.Analyzer
+	for _, a := range analyzers {
+		if a.Name == "panicNil" {
+			panicNil = a
+		}
+	}
+	if panicNil == nil {
+		t.Fatalf("panicNil analyzer not found")
+	}
+	if panicNil.Flags.Lookup("skipNilEfaceLit") == nil {
+		t.Errorf("checker params are not mapped to analyzer flags")
+	}
+
+	const src = `package example
+
+func f() {
+	panic(nil)
+	panic("ok")
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "example.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	pkg, err := new(types.Config).Check("example", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+
+	var diagnostics []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer:  panicNil,
+		Fset:      fset,
+		Files:     []*ast.File{f},
+		Pkg:       pkg,
+		TypesInfo: info,
+		Report: func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		},
+	}
+	if _, err := panicNil.Run(pass); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diagnostics))
+	}
+	d := diagnostics[0]
+	if have := fset.Position(d.Pos).Line; have != 4 {
+		t.Errorf("diagnostic line: have %d, want 4", have)
+	}
+	if want := "panic(nil) calls are discouraged"; d.Message != want {
+		t.Errorf("diagnostic message:\nhave: %q\nwant: %q", d.Message, want)
+	}
+}
+
+// mustFact marks functions that panic on errors.
+type mustFact struct{}
+
+func (*mustFact) AFact() {}
+
+// mustWalker marks functions with "must" name prefix
+// and reports calls of the marked functions.
+type mustWalker struct {
+	ctx *lintpack.CheckerContext
+}
+
+func (w *mustWalker) WalkFile(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if strings.HasPrefix(n.Name.Name, "Must") {
+				w.ctx.ExportObjectFact(w.ctx.TypesInfo.Defs[n.Name], &mustFact{})
+			}
+		case *ast.SelectorExpr:
+			if obj := w.ctx.TypesInfo.Uses[n.Sel]; obj != nil && w.ctx.ImportObjectFact(obj, &mustFact{}) {
+				w.ctx.Warn(n, "%s may panic", n.Sel.Name)
+			}
+		}
+		return true
+	})
+}
+
+func TestFacts(t *testing.T) {
+	info := &lintpack.CheckerInfo{
+		Name:      "lintanalysisFactTest",
+		Summary:   "Example",
+		FactTypes: []lintpack.Fact{new(mustFact)},
+	}
+	coll := &lintpack.CheckerCollection{URL: "example.com"}
+	coll.AddChecker(info, func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+		return &mustWalker{ctx: ctx}
+	})
+	a := NewAnalyzer(info)
+	if err := analysis.Validate([]*analysis.Analyzer{a}); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(a.FactTypes) == 0 {
+		t.Fatalf("fact types are not declared")
+	}
+
+	// facts emulates the analysis driver facts storage.
+	facts := make(map[*types.Package]analysis.Fact)
+	fset := token.NewFileSet()
+	pkgs := make(map[string]*types.Package)
+	var diagnostics []string
+	check := func(path, src string) {
+		f, err := parser.ParseFile(fset, path+".go", src, 0)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		info := &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+		}
+		cfg := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+			return pkgs[path], nil
+		})}
+		pkg, err := cfg.Check(path, fset, []*ast.File{f}, info)
+		if err != nil {
+			t.Fatalf("typecheck: %v", err)
+		}
+		pkgs[path] = pkg
+
+		pass := &analysis.Pass{
+			Analyzer:  a,
+			Fset:      fset,
+			Files:     []*ast.File{f},
+			Pkg:       pkg,
+			TypesInfo: info,
+			Report: func(d analysis.Diagnostic) {
+				diagnostics = append(diagnostics, fmt.Sprintf("%s: %s", fset.Position(d.Pos), d.Message))
+			},
+			ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+				stored, ok := facts[pkg]
+				if ok {
+					reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+				}
+				return ok
+			},
+			ExportPackageFact: func(fact analysis.Fact) {
+				facts[pkg] = fact
+			},
+		}
+		if _, err := a.Run(pass); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	}
+
+	check("dep", "package dep\n\nfunc MustF() {}\n\nfunc F() {}\n")
+	check("mid", "package mid\n\nimport _ \"dep\"\n")
+	check("main", "package main\n\nimport (\n\t\"dep\"\n\t_ \"mid\"\n)\n\nfunc main() {\n\tdep.F()\n\tdep.MustF()\n}\n")
+
+	want := []string{"main.go:10:2: MustF may panic"}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("diagnostics mismatch:\nhave: %q\nwant: %q", diagnostics, want)
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -0,0 +1,342 @@
+package check
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// baselineDoc is a baseline file contents.
+type baselineDoc struct {
+	Linter  string          `json:"linter"`
+	Entries []baselineEntry `json:"entries"`
+}
+
+// baselineEntry describes accepted warnings with the same fingerprint.
+//
+// Only Fingerprint is used for matching, other fields
+// make the file readable and are used in the fixed entries report.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+
+	// File is a slash-separated path relative to the baseline file directory.
+	File    string `json:"file"`
+	Checker string `json:"checker"`
+	Message string `json:"message"`
+
+	// Count is a number of identical warnings.
+	Count int `json:"count"`
+}
+
+// baseline is a set of accepted warnings.
+//
+// Warnings are identified by fingerprints that don't depend
+// on their line numbers, so they survive unrelated code changes.
+type baseline struct {
+	// dir is an absolute baseline file directory.
+	// Warning paths are relative to it, so the baseline
+	// doesn't depend on the working directory.
+	dir string
+
+	// entries maps fingerprints to the baseline entries.
+	entries map[string]*baselineEntry
+
+	// matched counts reported warnings for every fingerprint.
+	matched map[string]int
+
+	// checkedFiles is a set of checked files paths, relative to dir.
+	// Entries of other files are never reported as fixed.
+	checkedFiles map[string]bool
+
+	// sources holds checked files contents, loaded on demand.
+	sources map[string][]byte
+}
+
+// newBaseline returns an empty baseline for the given baseline file.
+func newBaseline(filename string) (*baseline, error) {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	return &baseline{
+		dir:          dir,
+		entries:      make(map[string]*baselineEntry),
+		matched:      make(map[string]int),
+		checkedFiles: make(map[string]bool),
+		sources:      make(map[string][]byte),
+	}, nil
+}
+
+// loadBaseline reads the baseline file written by -writeBaseline.
+func loadBaseline(filename string) (*baseline, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var doc baselineDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	b, err := newBaseline(filename)
+	if err != nil {
+		return nil, err
+	}
+	for i := range doc.Entries {
+		e := &doc.Entries[i]
+		if prev := b.entries[e.Fingerprint]; prev != nil {
+			prev.Count += e.Count
+			continue
+		}
+		b.entries[e.Fingerprint] = e
+	}
+	return b, nil
+}
+
+// relPath returns a slash-separated file path relative to the baseline directory.
+func (b *baseline) relPath(filename string) string {
+	rel, err := filepath.Rel(b.dir, filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// add records a warning with the given fingerprint.
+func (b *baseline) add(fingerprint, file, checker, message string) {
+	e := b.entries[fingerprint]
+	if e == nil {
+		e = &baselineEntry{
+			Fingerprint: fingerprint,
+			File:        file,
+			Checker:     checker,
+			Message:     message,
+		}
+		b.entries[fingerprint] = e
+	}
+	e.Count++
+}
+
+// match reports whether a warning with the given fingerprint
+// is accepted by the baseline.
+//
+// Every entry accepts at most Count warnings, so new
+// warnings that are identical to the old ones are still reported.
+func (b *baseline) match(fingerprint string) bool {
+	e := b.entries[fingerprint]
+	if e == nil || b.matched[fingerprint] >= e.Count {
+		return false
+	}
+	b.matched[fingerprint]++
+	return true
+}
+
+// fixed returns entries of the checked files that matched
+// less warnings than they accept, sorted by their file name.
+// Count of every returned entry is a number of fixed warnings.
+func (b *baseline) fixed() []baselineEntry {
+	var list []baselineEntry
+	for _, e := range b.sortedEntries() {
+		if !b.checkedFiles[e.File] {
+			continue
+		}
+		if n := e.Count - b.matched[e.Fingerprint]; n > 0 {
+			e.Count = n
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// write saves the baseline in a format that is read by loadBaseline.
+func (b *baseline) write(filename, linterName string) error {
+	doc := baselineDoc{
+		Linter:  linterName,
+		Entries: b.sortedEntries(),
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+func (b *baseline) sortedEntries() []baselineEntry {
+	list := make([]baselineEntry, 0, len(b.entries))
+	for _, e := range b.entries {
+		list = append(list, *e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		x, y := list[i], list[j]
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		if x.Checker != y.Checker {
+			return x.Checker < y.Checker
+		}
+		if x.Message != y.Message {
+			return x.Message < y.Message
+		}
+		return x.Fingerprint < y.Fingerprint
+	})
+	return list
+}
+
+// warningFingerprint returns a warning identifier that consists of
+// the file path, checker name, warning message, enclosing
+// declaration and normalized source snippet of the warning node.
+func warningFingerprint(fset *token.FileSet, src []byte, path, checker, decl string, warn lintpack.Warning) string {
+	h := sha256.New()
+	for _, s := range []string{
+		path,
+		checker,
+		warn.Text,
+		decl,
+		sourceSnippet(fset, src, warn.Node),
+	} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// enclosingDecl describes a top-level declaration that contains pos,
+// like "func (*T) Method" or "type T".
+// Returns empty string if pos is outside of any declaration.
+func enclosingDecl(f *ast.File, pos token.Pos) string {
+	if f == nil {
+		return ""
+	}
+	for _, decl := range f.Decls {
+		if pos < decl.Pos() || pos >= decl.End() {
+			continue
+		}
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil && len(decl.Recv.List) != 0 {
+				recv := types.ExprString(decl.Recv.List[0].Type)
+				return "func (" + recv + ") " + decl.Name.Name
+			}
+			return "func " + decl.Name.Name
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if spec.Pos() <= pos && pos < spec.End() {
+					return decl.Tok.String() + " " + specNames(spec)
+				}
+			}
+			return decl.Tok.String()
+		}
+	}
+	return ""
+}
+
+// warningDecl returns the enclosing declaration of the warning node.
+// Declarations of the replayed warnings are taken from the cache.
+func (l *linter) warningDecl(n ast.Node) string {
+	if n, ok := n.(*cachedNode); ok {
+		return n.decl
+	}
+	return enclosingDecl(l.pkgFiles[l.fset.File(n.Pos())], n.Pos())
+}
+
+func specNames(spec ast.Spec) string {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Name.Name
+	case *ast.ValueSpec:
+		names := make([]string, len(spec.Names))
+		for i, id := range spec.Names {
+			names[i] = id.Name
+		}
+		return strings.Join(names, ",")
+	case *ast.ImportSpec:
+		return spec.Path.Value
+	default:
+		return ""
+	}
+}
+
+// sourceSnippet returns the node source text, up to the end of its
+// first line, with all whitespace sequences replaced by a single space.
+//
+// The rest of the node is not included, so edits inside of
+// the big nodes, like function bodies, don't change the snippet.
+func sourceSnippet(fset *token.FileSet, src []byte, n ast.Node) string {
+	start := fset.Position(n.Pos()).Offset
+	end := fset.Position(n.End()).Offset
+	if start < 0 || end > len(src) || start > end {
+		return ""
+	}
+	text := src[start:end]
+	if i := bytes.IndexByte(text, '\n'); i != -1 {
+		text = text[:i]
+	}
+	return strings.Join(strings.Fields(string(text)), " ")
+}
+
+// isBaselined reports whether the warning is accepted by -baseline.
+// With -writeBaseline, the warning is recorded and accepted.
+func (l *linter) isBaselined(info *lintpack.CheckerInfo, warn lintpack.Warning) bool {
+	if l.baseline == nil {
+		return false
+	}
+	tf := l.fset.File(warn.Node.Pos())
+	if tf == nil {
+		return false
+	}
+	path := l.baseline.relPath(tf.Name())
+	src, ok := l.baseline.sources[tf.Name()]
+	if !ok {
+		src, _ = l.readFile(tf.Name()) // Snippet is empty on error
+		l.baseline.sources[tf.Name()] = src
+	}
+	decl := l.warningDecl(warn.Node)
+	fingerprint := warningFingerprint(l.fset, src, path, info.Name, decl, warn)
+	if l.writeBaselineFile != "" {
+		l.baseline.add(fingerprint, path, info.Name, warn.Text)
+		return true
+	}
+	return l.baseline.match(fingerprint)
+}
+
+// finishBaseline writes the -writeBaseline file or reports
+// -baseline entries that were fixed.
+func (l *linter) finishBaseline() error {
+	switch {
+	case l.baseline == nil:
+		return nil
+	case l.writeBaselineFile != "":
+		if err := l.baseline.write(l.writeBaselineFile, l.name); err != nil {
+			return err
+		}
+		if l.verbose {
+			log.Printf("\tdebug: wrote %d baseline entries to %s",
+				len(l.baseline.entries), l.writeBaselineFile)
+		}
+		return nil
+	}
+
+	fixed := l.baseline.fixed()
+	if len(fixed) == 0 {
+		return nil
+	}
+	total := 0
+	for _, e := range fixed {
+		total += e.Count
+		filename := filepath.Join(l.baseline.dir, filepath.FromSlash(e.File))
+		log.Printf("%s: %s: fixed: %s (%d)", relativePath(filename), e.Checker, e.Message, e.Count)
+	}
+	log.Printf("%d baseline warnings are fixed, update the baseline with -writeBaseline", total)
+	return nil
+}
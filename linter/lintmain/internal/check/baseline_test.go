@@ -0,0 +1,155 @@
+package check
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+func TestWarningFingerprint(t *testing.T) {
+	const src = `package example
+
+func f() {
+	x := 1 + 1
+	_ = x
+}
+`
+	const shifted = `package example
+
+// g is a new function.
+func g() {}
+
+func f() {
+	// Unrelated comment.
+	x  :=  1 + 1
+	_ = x
+}
+`
+	const changed = `package example
+
+func f() {
+	x := 1 + 2
+	_ = x
+}
+`
+
+	fingerprint := func(src, text string) string {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "example.go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var node ast.Node
+		ast.Inspect(f, func(n ast.Node) bool {
+			if n, ok := n.(*ast.AssignStmt); ok && node == nil {
+				node = n
+			}
+			return true
+		})
+		warn := lintpack.Warning{Node: node, Text: text}
+		decl := enclosingDecl(f, node.Pos())
+		return warningFingerprint(fset, []byte(src), "example.go", "checker", decl, warn)
+	}
+
+	base := fingerprint(src, "message")
+	if have := fingerprint(shifted, "message"); have != base {
+		t.Errorf("fingerprint changed after line shift")
+	}
+	if have := fingerprint(changed, "message"); have == base {
+		t.Errorf("fingerprint is not changed after snippet change")
+	}
+	if have := fingerprint(src, "other message"); have == base {
+		t.Errorf("fingerprint is not changed after message change")
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "baseline.json")
+	b, err := newBaseline(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.add("a", "x.go", "checker", "msg a")
+	b.add("a", "x.go", "checker", "msg a")
+	b.add("b", "y.go", "checker", "msg b")
+	b.add("c", "z.go", "checker", "msg c")
+	b.add("d", "w.go", "checker", "msg d")
+
+	if err := b.write(filename, "linter"); err != nil {
+		t.Fatal(err)
+	}
+	b, err = loadBaseline(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.checkedFiles["x.go"] = true
+	b.checkedFiles["y.go"] = true
+
+	for _, test := range []struct {
+		fingerprint string
+		want        bool
+	}{
+		{"a", true},
+		{"new", false},
+		{"c", true},
+		{"c", false},
+	} {
+		if have := b.match(test.fingerprint); have != test.want {
+			t.Errorf("match(%q): have %v, want %v", test.fingerprint, have, test.want)
+		}
+	}
+
+	// Only one of two "a" warnings is left.
+	// w.go is not checked, so its entry is not reported.
+	want := []baselineEntry{
+		{Fingerprint: "a", File: "x.go", Checker: "checker", Message: "msg a", Count: 1},
+		{Fingerprint: "b", File: "y.go", Checker: "checker", Message: "msg b", Count: 1},
+	}
+	if have := b.fixed(); !reflect.DeepEqual(have, want) {
+		t.Errorf("fixed entries:\nhave: %+v\nwant: %+v", have, want)
+	}
+}
+
+func TestBaselinePaths(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	subdir := filepath.Join(dir, "pkg")
+	if err := os.Mkdir(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// The same baseline file is used from the project root
+	// and from its subdirectory.
+	filename := filepath.Join(subdir, "a.go")
+	for _, test := range []struct {
+		wd       string
+		baseline string
+	}{
+		{dir, "baseline.json"},
+		{subdir, filepath.Join("..", "baseline.json")},
+	} {
+		if err := os.Chdir(test.wd); err != nil {
+			t.Fatal(err)
+		}
+		b, err := newBaseline(test.baseline)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := b.relPath(filename); have != "pkg/a.go" {
+			t.Errorf("%s: have %q, want %q", test.wd, have, "pkg/a.go")
+		}
+	}
+}
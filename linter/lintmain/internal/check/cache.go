@@ -0,0 +1,545 @@
+package check
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-lintpack/lintpack"
+	"golang.org/x/tools/go/packages"
+)
+
+// cacheRecord is a warning reported for the package,
+// in a form that doesn't depend on the loaded program.
+//
+// Records are collected before the -minSeverity, baseline and diff
+// filters are applied, so these settings don't affect the cache keys.
+type cacheRecord struct {
+	Checker  string
+	Filename string
+
+	// Start and End are the warning node offsets.
+	Start int
+	End   int
+
+	Text     string
+	Severity lintpack.Severity
+
+	// Decl is the enclosing declaration, see enclosingDecl.
+	Decl string
+
+	Fix []cacheEdit
+}
+
+// cacheEdit is a lintpack.TextEdit that uses file offsets.
+type cacheEdit struct {
+	Filename string
+	Start    int
+	End      int
+	NewText  string
+}
+
+// cacheEntry is a cached result of the package check.
+type cacheEntry struct {
+	// Files is a list of checked files, excluding skipped ones.
+	Files []string
+
+	Records []cacheRecord
+
+	// Facts are facts exported by the checkers for the package.
+	Facts []lintpack.EncodedFact
+}
+
+// resultCache stores package check results on disk.
+//
+// Results are keyed by the package files contents, keys of its
+// dependencies, enabled checkers and all settings that affect them,
+// including the linter executable itself, so cached packages are
+// neither type-checked nor checked again.
+//
+// Entries that are not used for cacheMaxAge are removed by trim.
+type resultCache struct {
+	dir string
+
+	// hits maps package IDs to the cached results.
+	hits map[string]*cacheEntry
+
+	// keys maps package IDs to their cache keys.
+	keys map[string]string
+
+	// current is a result of the package being checked.
+	// Nil if it's not recorded.
+	current *cacheEntry
+
+	// files maps names of the files with replayed warnings
+	// to their token.File objects.
+	files map[string]*token.File
+
+	// infos maps checker names to their info objects.
+	infos map[string]*lintpack.CheckerInfo
+}
+
+// cachedNode is an ast.Node of the replayed warning.
+type cachedNode struct {
+	pos  token.Pos
+	end  token.Pos
+	decl string
+}
+
+func (n *cachedNode) Pos() token.Pos { return n.pos }
+func (n *cachedNode) End() token.Pos { return n.end }
+
+// newResultCache returns a cache that is located in the user
+// cache directory. Returns nil if there is no such directory.
+func newResultCache(linterName string) *resultCache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return &resultCache{
+		dir:   filepath.Join(dir, "lintpack", linterName),
+		hits:  make(map[string]*cacheEntry),
+		keys:  make(map[string]string),
+		files: make(map[string]*token.File),
+		infos: make(map[string]*lintpack.CheckerInfo),
+	}
+}
+
+func (cache *resultCache) filename(key string) string {
+	return filepath.Join(cache.dir, key[:2], key+".gob")
+}
+
+// get returns a cached entry for the key.
+// Returns nil if there is no valid entry.
+//
+// Modification time of the used entries is updated,
+// so they are not removed by trim.
+func (cache *resultCache) get(key string) *cacheEntry {
+	filename := cache.filename(key)
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var entry cacheEntry
+	if err := gob.NewDecoder(f).Decode(&entry); err != nil {
+		return nil
+	}
+	if fi, err := f.Stat(); err == nil {
+		if now := time.Now(); now.Sub(fi.ModTime()) > cacheMtimeInterval {
+			os.Chtimes(filename, now, now) // Best effort
+		}
+	}
+	return &entry
+}
+
+const (
+	// cacheMaxAge is the time after which unused entries are removed.
+	cacheMaxAge = 5 * 24 * time.Hour
+
+	// cacheTrimInterval is the minimal time between trims.
+	cacheTrimInterval = 24 * time.Hour
+
+	// cacheMtimeInterval is the precision of the entries modification time.
+	// Used entries are only touched if they are older than that.
+	cacheMtimeInterval = time.Hour
+)
+
+// trim removes entries that were not used for cacheMaxAge,
+// along with temporary files left by interrupted runs.
+//
+// The last trim time is stored in the "trim.txt" file,
+// so the cache is trimmed at most once in cacheTrimInterval.
+func (cache *resultCache) trim(now time.Time) error {
+	marker := filepath.Join(cache.dir, "trim.txt")
+	if fi, err := os.Stat(marker); err == nil && now.Sub(fi.ModTime()) < cacheTrimInterval {
+		return nil
+	}
+
+	dirs, err := ioutil.ReadDir(cache.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, err := ioutil.ReadDir(filepath.Join(cache.dir, dir.Name()))
+		if err != nil {
+			return err
+		}
+		for _, fi := range files {
+			name := fi.Name()
+			if !strings.HasSuffix(name, ".gob") && !strings.HasSuffix(name, ".tmp") {
+				continue
+			}
+			if now.Sub(fi.ModTime()) > cacheMaxAge {
+				os.Remove(filepath.Join(cache.dir, dir.Name(), name))
+			}
+		}
+	}
+	return ioutil.WriteFile(marker, []byte(now.Format(time.RFC3339)+"\n"), 0644)
+}
+
+// put saves the entry for the key.
+// The file is written atomically, so concurrent runs
+// never see partially written entries.
+func (cache *resultCache) put(key string, entry *cacheEntry) error {
+	filename := cache.filename(key)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(filename), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(entry); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// settingsHash returns a hash of the settings that
+// affect the results of every package.
+func (l *linter) settingsHash() string {
+	h := sha256.New()
+	fmt.Fprintln(h, l.name, l.version)
+	fmt.Fprintln(h, runtime.Version(), runtime.GOOS, runtime.GOARCH, os.Getenv("GOFLAGS"))
+	fmt.Fprintln(h, l.checkTests, l.checkGenerated, l.requireIgnoreReason, l.reportUnusedIgnores)
+	fmt.Fprintln(h, l.configDir, l.excludes)
+	overrides, _ := json.Marshal(l.configOverrides)
+	fmt.Fprintf(h, "%s\n", overrides)
+
+	var params []string
+	for _, info := range l.infoList {
+		for pname, param := range info.Params {
+			params = append(params, l.checkerParamKey(info.Name, pname)+"="+param.String())
+		}
+	}
+	sort.Strings(params)
+	fmt.Fprintln(h, params)
+
+	// The linter executable and the plugin define the checkers
+	// behavior, so the results of the different builds never mix up.
+	// A run that can't read the executable never hits the cache.
+	if executable, err := os.Executable(); err != nil || hashFile(h, executable) != nil {
+		fmt.Fprintln(h, time.Now().UnixNano())
+	}
+	hashFile(h, pluginFilename)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashFile writes the file contents to the hash.
+func hashFile(h io.Writer, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+// packageKeys computes cache keys for the loaded packages.
+//
+// Loaded packages are identified by their source keys, that are derived
+// from their files and the keys of their dependencies.
+// Other dependencies, including the standard library, are not checked,
+// so they are identified by their export data that describes everything
+// the dependent packages can observe. Dependencies without export data
+// are identified by their source, recursively.
+//
+// Loaded packages must include import information and export data
+// file names for their dependencies.
+func (l *linter) packageKeys(pkgs []*packages.Package) (map[string]string, error) {
+	settings := l.settingsHash()
+
+	loaded := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		loaded[pkg.ID] = true
+	}
+
+	srcKeys := make(map[string]string)
+	var srcKey func(pkg *packages.Package) (string, error)
+	srcKey = func(pkg *packages.Package) (string, error) {
+		if key, ok := srcKeys[pkg.ID]; ok {
+			return key, nil
+		}
+		h := sha256.New()
+		fmt.Fprintln(h, pkg.ID)
+		if !loaded[pkg.ID] && pkg.ExportFile != "" {
+			fmt.Fprintln(h, "export")
+			if err := hashFile(h, pkg.ExportFile); err != nil {
+				return "", err
+			}
+			key := hex.EncodeToString(h.Sum(nil))
+			srcKeys[pkg.ID] = key
+			return key, nil
+		}
+		for _, filename := range pkg.GoFiles {
+			src, err := l.readFile(filename)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintln(h, filename, len(src))
+			h.Write(src)
+		}
+		paths := make([]string, 0, len(pkg.Imports))
+		for path := range pkg.Imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			key, err := srcKey(pkg.Imports[path])
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintln(h, path, key)
+		}
+		key := hex.EncodeToString(h.Sum(nil))
+		srcKeys[pkg.ID] = key
+		return key, nil
+	}
+
+	keys := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		key, err := srcKey(pkg)
+		if err != nil {
+			return nil, err
+		}
+		checkers, err := l.checkersFor(pkg)
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		fmt.Fprintln(h, settings, key)
+		for _, c := range checkers {
+			fmt.Fprintln(h, c.Info.Name)
+		}
+		keys[pkg.ID] = hex.EncodeToString(h.Sum(nil))
+	}
+	return keys, nil
+}
+
+// loadCachedResults finds cached results for the packages
+// and loads syntax for the packages that are not cached.
+//
+// loadProgram only loads import information and export data
+// if the cache is enabled.
+func (l *linter) loadCachedResults() error {
+	if l.cache == nil {
+		return nil
+	}
+	cache := l.cache
+
+	keys, err := l.packageKeys(l.loadedPackages)
+	if err != nil {
+		return err
+	}
+	cache.keys = keys
+
+	var missed []string
+	for _, pkg := range l.loadedPackages {
+		if entry := cache.get(keys[pkg.ID]); entry != nil {
+			cache.hits[pkg.ID] = entry
+			continue
+		}
+		missed = append(missed, pkg.PkgPath)
+	}
+	if l.verbose {
+		log.Printf("\tdebug: %d of %d packages are cached",
+			len(cache.hits), len(l.loadedPackages))
+	}
+	if err := cache.trim(time.Now()); err != nil && l.verbose {
+		log.Printf("\tdebug: can't trim the cache: %v", err)
+	}
+	for _, info := range l.infoList {
+		cache.infos[info.Name] = info
+	}
+	cache.infos[suppressionInfo.Name] = suppressionInfo
+	if len(missed) == 0 {
+		return nil
+	}
+
+	patterns := uniqueStrings(missed)
+	for _, path := range patterns {
+		if path == "command-line-arguments" {
+			// Packages that are given as a list of files
+			// can't be loaded by their path.
+			patterns = l.packages
+			break
+		}
+	}
+	cfg := *l.loadConfig
+	cfg.Mode = packages.LoadSyntax
+	pkgs, err := loadPackages(&cfg, patterns)
+	if err != nil {
+		return err
+	}
+	loaded := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		loaded[pkg.ID] = pkg
+	}
+	for i, pkg := range l.loadedPackages {
+		if cache.hits[pkg.ID] != nil {
+			continue
+		}
+		syntax := loaded[pkg.ID]
+		if syntax == nil {
+			return fmt.Errorf("%s: package is not loaded", pkg.ID)
+		}
+		l.loadedPackages[i] = syntax
+	}
+	return nil
+}
+
+// startPackage starts recording warnings for the package.
+func (cache *resultCache) startPackage() {
+	cache.current = &cacheEntry{}
+}
+
+// finishPackage saves the recorded package results.
+// Results are not saved if any checker crashed.
+func (l *linter) finishPackage(pkg *packages.Package, crashed bool) {
+	cache := l.cache
+	entry := cache.current
+	cache.current = nil
+	if crashed {
+		return
+	}
+	entry.Facts = l.ctx.PackageFacts(pkg.PkgPath)
+	if err := cache.put(cache.keys[pkg.ID], entry); err != nil && l.verbose {
+		log.Printf("\tdebug: %s: can't save results: %v", pkg.String(), err)
+	}
+}
+
+// recordWarning adds the warning to the current package results.
+func (l *linter) recordWarning(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	pos := l.fset.Position(warn.Node.Pos())
+	r := cacheRecord{
+		Checker:  info.Name,
+		Filename: pos.Filename,
+		Start:    pos.Offset,
+		End:      l.fset.Position(warn.Node.End()).Offset,
+		Text:     warn.Text,
+		Severity: warn.Severity,
+		Decl:     l.warningDecl(warn.Node),
+	}
+	for _, e := range warn.Fix {
+		if !e.Pos.IsValid() || !e.End.IsValid() {
+			r.Fix = nil // Reported by addFix
+			break
+		}
+		start := l.fset.Position(e.Pos)
+		r.Fix = append(r.Fix, cacheEdit{
+			Filename: start.Filename,
+			Start:    start.Offset,
+			End:      l.fset.Position(e.End).Offset,
+			NewText:  e.NewText,
+		})
+	}
+	l.cache.current.Records = append(l.cache.current.Records, r)
+}
+
+// replayPackage reports cached package results
+// as if the package was checked.
+func (l *linter) replayPackage(pkg *packages.Package, entry *cacheEntry) error {
+	if l.verbose {
+		log.Printf("\tdebug: using cached results for %q package", pkg.String())
+	}
+	// Reporters describe the package checkers, like their params.
+	checkers, err := l.checkersFor(pkg)
+	if err != nil {
+		return err
+	}
+	l.checkers = checkers
+	l.ctx.AddPackageFacts(pkg.PkgPath, entry.Facts)
+	for _, filename := range entry.Files {
+		l.markChecked(filename)
+	}
+
+	for _, r := range entry.Records {
+		info := l.cache.infos[r.Checker]
+		tf := l.cachedFile(r.Filename)
+		if info == nil || tf == nil || r.End > tf.Size() {
+			continue // Stale record
+		}
+		warn := lintpack.Warning{
+			Node: &cachedNode{
+				pos:  tf.Pos(r.Start),
+				end:  tf.Pos(r.End),
+				decl: r.Decl,
+			},
+			Text:     r.Text,
+			Severity: r.Severity,
+		}
+		for _, e := range r.Fix {
+			ef := l.cachedFile(e.Filename)
+			if ef == nil || e.End > ef.Size() {
+				warn.Fix = nil
+				break
+			}
+			warn.Fix = append(warn.Fix, lintpack.TextEdit{
+				Pos:     ef.Pos(e.Start),
+				End:     ef.Pos(e.End),
+				NewText: e.NewText,
+			})
+		}
+		l.report(info, warn)
+	}
+	return nil
+}
+
+// cachedFile returns a token.File for the file with replayed warnings.
+// Returns nil if the file can't be read.
+func (l *linter) cachedFile(filename string) *token.File {
+	if tf, ok := l.cache.files[filename]; ok {
+		return tf
+	}
+	var tf *token.File
+	if src, err := l.readFile(filename); err == nil {
+		tf = l.fset.AddFile(filename, -1, len(src))
+		tf.SetLinesForContent(src)
+	}
+	l.cache.files[filename] = tf
+	return tf
+}
+
+// uniqueStrings returns list without duplicates, preserving the order.
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	result := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// isCacheable reports whether warnings of the current package are recorded.
+func (l *linter) isCacheable() bool {
+	return l.cache != nil && l.cache.current != nil
+}
@@ -0,0 +1,236 @@
+package check
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-lintpack/lintpack"
+	"golang.org/x/tools/go/packages"
+)
+
+// collectReporter records warnings as "pos: checker: text" lines.
+type collectReporter struct {
+	l        *linter
+	warnings []string
+}
+
+func (r *collectReporter) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	pos := r.l.fset.Position(warn.Node.Pos())
+	end := r.l.fset.Position(warn.Node.End())
+	r.warnings = append(r.warnings, fmt.Sprintf("%s-%d:%d: %s: %s",
+		pos, end.Line, end.Column, info.Name, warn.Text))
+}
+
+func (r *collectReporter) Finish() error { return nil }
+
+func TestCacheReplay(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.go")
+	src := "package a\n\nfunc f() {\n\tprintln(1 + 1)\n}\n"
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := &lintpack.CheckerInfo{Name: "example", Collection: &lintpack.CheckerCollection{}}
+	newLinter := func() (*linter, *collectReporter) {
+		l := &linter{fset: token.NewFileSet(), cache: newResultCache("test")}
+		l.cache.dir = filepath.Join(dir, "cache")
+		l.cache.infos[info.Name] = info
+		l.ctx = lintpack.NewContext(l.fset, nil)
+		r := &collectReporter{l: l}
+		l.reporter = r
+		return l, r
+	}
+
+	// Check the file and record the results.
+	l, r := newLinter()
+	f, err := parser.ParseFile(l.fset, filename, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := f.Decls[0].(*ast.FuncDecl).Body.List[0]
+	l.cache.startPackage()
+	l.markChecked(filename)
+	l.report(info, lintpack.Warning{
+		Node:     call,
+		Text:     "example warning",
+		Severity: lintpack.SeverityWarning,
+		Fix:      []lintpack.TextEdit{{Pos: call.Pos(), End: call.End(), NewText: "println(2)"}},
+	})
+	if err := l.cache.put("0123", l.cache.current); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replay the results with a different file set.
+	l2, r2 := newLinter()
+	l2.fset.AddFile("other.go", -1, 100)
+	entry := l2.cache.get("0123")
+	if entry == nil {
+		t.Fatal("cached entry is not found")
+	}
+	if !reflect.DeepEqual(entry.Files, []string{filename}) {
+		t.Errorf("checked files: have %q, want %q", entry.Files, filename)
+	}
+	l2.replayPackage(&packages.Package{ID: "a", PkgPath: "a"}, entry)
+
+	if !reflect.DeepEqual(r2.warnings, r.warnings) {
+		t.Errorf("replayed warnings mismatch:\nhave: %q\nwant: %q", r2.warnings, r.warnings)
+	}
+	if !reflect.DeepEqual(l2.fixes, l.fixes) {
+		t.Errorf("replayed fixes mismatch:\nhave: %+v\nwant: %+v", l2.fixes, l.fixes)
+	}
+}
+
+func TestPackageKeys(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	dep := &packages.Package{
+		ID:      "example.com/dep",
+		PkgPath: "example.com/dep",
+		GoFiles: []string{write("dep.go", "package dep\n")},
+	}
+	pkg := &packages.Package{
+		ID:      "example.com/pkg",
+		PkgPath: "example.com/pkg",
+		GoFiles: []string{write("pkg.go", "package pkg\n")},
+		Imports: map[string]*packages.Package{dep.PkgPath: dep},
+	}
+	other := &packages.Package{
+		ID:      "example.com/other",
+		PkgPath: "example.com/other",
+		GoFiles: []string{write("other.go", "package other\n")},
+	}
+
+	l := &linter{ctx: lintpack.NewContext(token.NewFileSet(), nil)}
+	info := &lintpack.CheckerInfo{Name: "example"}
+	keys := func() map[string]string {
+		keys, err := l.packageKeys([]*packages.Package{pkg, other})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return keys
+	}
+
+	base := keys()
+	if base[pkg.ID] == base[other.ID] {
+		t.Fatalf("different packages have identical keys")
+	}
+	if have := keys(); !reflect.DeepEqual(have, base) {
+		t.Errorf("keys are not stable")
+	}
+
+	write("dep.go", "package dep\n\nfunc F() {}\n")
+	changed := keys()
+	if changed[pkg.ID] == base[pkg.ID] {
+		t.Errorf("key is not changed after dependency change")
+	}
+	if changed[other.ID] != base[other.ID] {
+		t.Errorf("key is changed after unrelated package change")
+	}
+
+	l.baseCheckers = []*lintpack.Checker{{Info: info}}
+	withCheckers := keys()
+	if withCheckers[pkg.ID] == changed[pkg.ID] {
+		t.Errorf("key is not changed after checkers set change")
+	}
+
+	// Dependencies with export data are identified by it.
+	dep.ExportFile = write("dep.a", "export data 1")
+	exported := keys()
+	write("dep.go", "package dep\n\nfunc G() {}\n")
+	if have := keys(); have[pkg.ID] != exported[pkg.ID] {
+		t.Errorf("key is changed after dependency source change")
+	}
+	write("dep.a", "export data 2")
+	if have := keys(); have[pkg.ID] == exported[pkg.ID] {
+		t.Errorf("key is not changed after dependency export data change")
+	}
+
+	// Loaded dependencies are identified by their source,
+	// since their facts depend on it.
+	both := func() map[string]string {
+		keys, err := l.packageKeys([]*packages.Package{pkg, dep})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return keys
+	}
+	before := both()
+	write("dep.go", "package dep\n\nfunc H() {}\n")
+	if have := both(); have[pkg.ID] == before[pkg.ID] {
+		t.Errorf("key is not changed after loaded dependency change")
+	}
+}
+
+func TestCacheTrim(t *testing.T) {
+	dir := t.TempDir()
+	cache := newResultCache("test")
+	cache.dir = dir
+
+	for _, key := range []string{"0001", "0002"} {
+		if err := cache.put(key, &cacheEntry{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmp := filepath.Join(dir, "00", "0003.123.tmp")
+	if err := ioutil.WriteFile(tmp, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	old := now.Add(-cacheMaxAge - time.Hour)
+	for _, filename := range []string{cache.filename("0001"), cache.filename("0002"), tmp} {
+		if err := os.Chtimes(filename, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Used entries are kept.
+	if cache.get("0002") == nil {
+		t.Fatal("cached entry is not found")
+	}
+
+	if err := cache.trim(now); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		filename string
+		exists   bool
+	}{
+		{cache.filename("0001"), false},
+		{cache.filename("0002"), true},
+		{tmp, false},
+	} {
+		_, err := os.Stat(test.filename)
+		if exists := err == nil; exists != test.exists {
+			t.Errorf("%s: have exists=%v, want %v", test.filename, exists, test.exists)
+		}
+	}
+
+	// The cache is trimmed at most once in cacheTrimInterval.
+	if err := cache.put("0004", &cacheEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(cache.filename("0004"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.trim(now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cache.filename("0004")); err != nil {
+		t.Errorf("entry is removed before the trim interval: %v", err)
+	}
+}
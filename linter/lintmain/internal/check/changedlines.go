@@ -0,0 +1,220 @@
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// changedLines maps absolute file names to the sets of lines
+// that were added or modified by a diff.
+type changedLines map[string]map[int]bool
+
+// parseUnifiedDiff collects added lines of the unified diff.
+//
+// New file names are resolved relative to root.
+// The "b/" prefix of new file names, added by git, is removed.
+// Deleted files and removed lines are ignored.
+//
+// Hunks are read according to their line counts, so hunk lines
+// that look like file headers, like "--- x" for a removed "-- x" line,
+// are not confused with the headers of the next file.
+func parseUnifiedDiff(r io.Reader, root string) (changedLines, error) {
+	changed := make(changedLines)
+
+	var lines map[int]bool // Lines of the current file
+	line := 0              // Current line in the new file
+
+	// Old and new file lines that are left in the current hunk.
+	oldLeft, newLeft := 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		s := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(s, "+") && newLeft > 0:
+				if lines != nil {
+					lines[line] = true
+				}
+				line++
+				newLeft--
+			case strings.HasPrefix(s, "-") && oldLeft > 0:
+				oldLeft--
+			case (strings.HasPrefix(s, " ") || s == "") && oldLeft > 0 && newLeft > 0:
+				// Empty context lines can have their space trimmed.
+				line++
+				oldLeft--
+				newLeft--
+			case strings.HasPrefix(s, `\`):
+				// "\ No newline at end of file".
+			default:
+				return nil, fmt.Errorf("line %d: unexpected %q in hunk", n, s)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(s, "+++ "):
+			lines = nil
+			name := strings.TrimPrefix(s, "+++ ")
+			if tab := strings.IndexByte(name, '\t'); tab != -1 {
+				name = name[:tab] // Timestamp
+			}
+			if name == "/dev/null" {
+				continue
+			}
+			if unquoted, err := strconv.Unquote(name); err == nil {
+				name = unquoted
+			}
+			name = filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+			lines = changed[name]
+			if lines == nil {
+				lines = make(map[int]bool)
+				changed[name] = lines
+			}
+		case strings.HasPrefix(s, "@@ "):
+			h, err := parseHunkHeader(s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			line = h.newStart
+			oldLeft, newLeft = h.oldCount, h.newCount
+		default:
+			// Headers, like "diff --git", "index" or "--- a/file",
+			// and "\ No newline at end of file" after the last hunk line.
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, fmt.Errorf("line %d: unexpected end of hunk", n)
+	}
+	return changed, nil
+}
+
+// hunkHeader describes a hunk header, like "@@ -1,5 +1,6 @@".
+type hunkHeader struct {
+	oldCount int
+	newStart int
+	newCount int
+}
+
+// parseHunkHeader parses the hunk line ranges.
+// Omitted line counts are equal to 1.
+func parseHunkHeader(header string) (hunkHeader, error) {
+	var h hunkHeader
+	fields := strings.Fields(header)
+	if len(fields) < 4 || fields[3] != "@@" ||
+		!strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return h, fmt.Errorf("malformed hunk header %q", header)
+	}
+	_, oldCount, err1 := parseHunkRange(fields[1][len("-"):])
+	newStart, newCount, err2 := parseHunkRange(fields[2][len("+"):])
+	if err1 != nil || err2 != nil {
+		return h, fmt.Errorf("malformed hunk header %q", header)
+	}
+	h.oldCount = oldCount
+	h.newStart = newStart
+	h.newCount = newCount
+	return h, nil
+}
+
+// parseHunkRange parses a hunk range, like "1,5" or "3".
+func parseHunkRange(s string) (start, count int, err error) {
+	count = 1
+	if comma := strings.IndexByte(s, ','); comma != -1 {
+		count, err = strconv.Atoi(s[comma+1:])
+		if err != nil {
+			return 0, 0, err
+		}
+		s = s[:comma]
+	}
+	start, err = strconv.Atoi(s)
+	return start, count, err
+}
+
+// loadDiffFile reads changed lines from the patch file.
+// File names are resolved relative to the working directory.
+func loadDiffFile(filename string) (changedLines, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return parseUnifiedDiff(f, wd)
+}
+
+// loadGitDiff returns lines changed since the git revision,
+// including changes that are not committed yet.
+// Untracked files are not included.
+func loadGitDiff(revision string) (changedLines, error) {
+	root, err := gitRoot()
+	if err != nil {
+		return nil, err
+	}
+	diff, err := runGit("diff", "--no-color", "--no-ext-diff", "-U0",
+		"--src-prefix=a/", "--dst-prefix=b/", revision, "--")
+	if err != nil {
+		return nil, err
+	}
+	return parseUnifiedDiff(bytes.NewReader(diff), root)
+}
+
+// gitRoot returns the repository root directory.
+//
+// The root is derived from the working directory, since
+// "git rev-parse --show-toplevel" resolves symlinks, while the
+// packages loader keeps them. This way, file names under the root
+// match the loaded file names in checkouts reached through a symlink.
+func gitRoot() (string, error) {
+	cdup, err := runGit("rev-parse", "--show-cdup")
+	if err != nil {
+		return "", err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(wd, strings.TrimSpace(string(cdup))), nil
+}
+
+// runGit runs git command in the working directory and returns its output.
+func runGit(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s",
+			args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// isChanged reports whether the warning starts at the line
+// changed by -diffBase or -diffFile diff.
+// All warnings are changed if neither is set.
+func (l *linter) isChanged(warn lintpack.Warning) bool {
+	if l.changedLines == nil {
+		return true
+	}
+	pos := l.fset.Position(warn.Node.Pos())
+	return l.changedLines[pos.Filename][pos.Line]
+}
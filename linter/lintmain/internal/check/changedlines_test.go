@@ -0,0 +1,167 @@
+package check
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	const diff = `diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -3,3 +3,4 @@ import "fmt"
+ func f() {
+-	fmt.Println(1)
++	fmt.Println(2)
++	fmt.Println(3)
+ }
+@@ -20 +21,0 @@ func g() {
+-	return
+@@ -30,0 +30,2 @@ func h() {
++	x := 1
++
+\ No newline at end of file
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package old
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go	2019-01-01 00:00:00
+@@ -0,0 +1 @@
++package new
+`
+
+	root := filepath.FromSlash("/project")
+	have, err := parseUnifiedDiff(strings.NewReader(diff), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := changedLines{
+		filepath.Join(root, "pkg", "a.go"): {4: true, 5: true, 30: true, 31: true},
+		filepath.Join(root, "new.go"):      {1: true},
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("changed lines:\nhave: %v\nwant: %v", have, want)
+	}
+
+	if _, err := parseUnifiedDiff(strings.NewReader("+++ b/a.go\n@@ bad @@\n"), root); err == nil {
+		t.Errorf("expected an error for a malformed hunk header")
+	}
+	if _, err := parseUnifiedDiff(strings.NewReader("+++ b/a.go\n@@ -1,2 +1,2 @@\n a\n"), root); err == nil {
+		t.Errorf("expected an error for a truncated hunk")
+	}
+}
+
+func TestParseUnifiedDiffWithoutGitHeaders(t *testing.T) {
+	// Concatenated "diff -u" output. Hunk lines of a.go look like
+	// file headers, so hunks must be read according to their counts.
+	const diff = `--- a.go	2019-01-01 00:00:00
++++ a.go	2019-01-02 00:00:00
+@@ -1,3 +1,3 @@
+ package a
+--- removed comment
++++ added comment
+ var x = 1
+--- sub/b.go	2019-01-01 00:00:00
++++ sub/b.go	2019-01-02 00:00:00
+@@ -2 +2,2 @@
+-var y = 1
++var y = 2
++var z = 3
+`
+
+	root := filepath.FromSlash("/project")
+	have, err := parseUnifiedDiff(strings.NewReader(diff), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := changedLines{
+		filepath.Join(root, "a.go"):        {2: true},
+		filepath.Join(root, "sub", "b.go"): {2: true, 3: true},
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("changed lines:\nhave: %v\nwant: %v", have, want)
+	}
+}
+
+// chdirSymlink makes a symlink to dir the working directory,
+// like a shell does after cd to the symlink.
+// Returns a cleanup function that restores the working directory.
+func chdirSymlink(t *testing.T, dir string) (link string, cleanup func()) {
+	linkDir, err := ioutil.TempDir("", "lintpack-link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link = filepath.Join(linkDir, "project")
+	if err := os.Symlink(dir, link); err != nil {
+		os.RemoveAll(linkDir)
+		t.Skipf("can't create symlink: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(link); err != nil {
+		t.Fatal(err)
+	}
+	// os.Getwd and the go command report $PWD if it's
+	// the working directory, so the symlink is kept.
+	pwd, hasPWD := os.LookupEnv("PWD")
+	os.Setenv("PWD", link)
+	cleanup = func() {
+		if hasPWD {
+			os.Setenv("PWD", pwd)
+		} else {
+			os.Unsetenv("PWD")
+		}
+		os.Chdir(wd)
+		os.RemoveAll(linkDir)
+	}
+	return link, cleanup
+}
+
+func TestLoadGitDiffSymlink(t *testing.T) {
+	dir, cleanup := newGitRepo(t)
+	defer cleanup()
+
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/a\n")
+	writeTestFile(t, filepath.Join(dir, "a.go"), "package a\n")
+	mustRunGit(t, "add", ".")
+	mustRunGit(t, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "-m", "init")
+	writeTestFile(t, filepath.Join(dir, "a.go"), "package a\n\nvar x = 1\n")
+
+	link, cleanupLink := chdirSymlink(t, dir)
+	defer cleanupLink()
+
+	changed, err := loadGitDiff("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changed lines are matched against the loaded file names.
+	pkgs, err := loadPackages(&packages.Config{Mode: packages.LoadFiles}, []string{"."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 || len(pkgs[0].GoFiles) != 1 {
+		t.Fatalf("unexpected packages: %v", pkgs)
+	}
+	filename := pkgs[0].GoFiles[0]
+	if want := filepath.Join(link, "a.go"); filename != want {
+		t.Fatalf("loaded file: have %s, want %s", filename, want)
+	}
+	if want := map[int]bool{2: true, 3: true}; !reflect.DeepEqual(changed[filename], want) {
+		t.Errorf("changed lines of %s:\nhave: %v\nwant: %v", filename, changed, want)
+	}
+}
@@ -8,26 +8,35 @@ import (
 	"go/build"
 	"go/token"
 	"go/types"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"runtime/debug"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 
 	"github.com/go-lintpack/lintpack"
 	"github.com/go-lintpack/lintpack/linter/lintmain/internal/hotload"
 	"github.com/go-toolsmith/pkgload"
-	"github.com/logrusorgru/aurora"
 	"golang.org/x/tools/go/packages"
 )
 
 // Main implements sub-command entry point.
-func Main() {
+//
+// linterName and linterVersion describe the linter in reports.
+func Main(linterName, linterVersion string) {
 	var l linter
+	l.name = linterName
+	l.version = linterVersion
 	l.infoList = lintpack.GetCheckersInfo()
+	l.out = os.Stdout
 
 	steps := []struct {
 		name string
@@ -37,10 +46,13 @@ func Main() {
 		{"bind checker params", l.bindCheckerParams},
 		{"bind default enabled list", l.bindDefaultEnabledList},
 		{"parse args", l.parseArgs},
-		{"assign checker params", l.assignCheckerParams},
 		{"load program", l.loadProgram},
 		{"init checkers", l.initCheckers},
+		{"load cached results", l.loadCachedResults},
 		{"run checkers", l.runCheckers},
+		{"print report", l.printReport},
+		{"finish baseline", l.finishBaseline},
+		{"apply fixes", l.applyFixes},
 		{"exit if found issues", l.exit},
 	}
 
@@ -52,21 +64,105 @@ func Main() {
 }
 
 type linter struct {
+	// name and version describe the linter.
+	name    string
+	version string
+
 	ctx *lintpack.Context
 
 	fset *token.FileSet
 
 	loadedPackages []*packages.Package
 
+	// loadConfig is a config that was used to load the packages.
+	loadConfig *packages.Config
+
+	// cache stores package results between runs.
+	// Nil if caching is disabled.
+	cache *resultCache
+
 	infoList []*lintpack.CheckerInfo
 
+	// checkers is a set of checkers for the current package.
 	checkers []*lintpack.Checker
 
+	// baseCheckers is a set of checkers selected by the flags and
+	// the config file, without the per-directory overrides.
+	baseCheckers []*lintpack.Checker
+
+	// overrides are per-directory checker settings.
+	overrides []*checkerOverride
+
+	// checkerSets caches checker sets for the packages affected
+	// by the overrides. Keys describe matched overrides.
+	checkerSets map[string][]*lintpack.Checker
+
 	packages []string
 
 	foundIssues bool
 
-	checkerParams boundCheckerParams
+	// maxSeverity is the most serious severity among reported warnings.
+	maxSeverity lintpack.Severity
+
+	// crashes records recovered checker panics.
+	crashes []*checkerCrash
+
+	// disabled is a set of checkers that crashed while
+	// checking the current package.
+	disabled map[*lintpack.Checker]bool
+
+	// reporter prints warnings in the selected output format.
+	reporter reporter
+
+	// out is where reports and -diff output are printed to.
+	out io.Writer
+
+	// formatTemplate is a user-defined output template
+	// for the template output format.
+	formatTemplate *template.Template
+
+	// settingSources maps flag names to the sources of their values.
+	// Flags with default values are not recorded.
+	settingSources map[string]string
+
+	// configDir is a config file directory.
+	// Exclude patterns are relative to it.
+	configDir string
+
+	// excludes is a list of file patterns that are not checked.
+	excludes []string
+
+	// configOverrides are per-directory settings from the config file.
+	configOverrides []configOverride
+
+	// suppressions maps current package files to their
+	// suppression directives.
+	suppressions map[*token.File][]*suppression
+
+	// pkgFiles maps current package files to their syntax trees.
+	pkgFiles map[*token.File]*ast.File
+
+	// baseline is a set of accepted warnings loaded by -baseline
+	// or collected for -writeBaseline. Nil if neither is set.
+	baseline *baseline
+
+	// writeBaselineFile is a -writeBaseline file name.
+	writeBaselineFile string
+
+	// changedLines is a set of lines changed by -diffBase or -diffFile diff.
+	// Nil if all lines are reported.
+	changedLines changedLines
+
+	// staged describes the git index contents under -staged.
+	// Nil if the working tree is checked.
+	staged *stagedSources
+
+	// overlay maps file names to the contents that were used
+	// to load the program instead of the files on disk.
+	overlay map[string][]byte
+
+	// fixes maps file names to the fixes suggested for them.
+	fixes map[string][]suggestedFix
 
 	filters struct {
 		enableAll       bool
@@ -79,143 +175,244 @@ type linter struct {
 	gopath  string
 	goroot  string
 
-	exitCode           int
-	checkTests         bool
-	checkGenerated     bool
-	shorterErrLocation bool
-	coloredOutput      bool
-	verbose            bool
+	format              string
+	exitCode            exitCodes
+	minSeverity         lintpack.Severity
+	checkTests          bool
+	checkGenerated      bool
+	shorterErrLocation  bool
+	coloredOutput       bool
+	verbose             bool
+	fix                 bool
+	requireIgnoreReason bool
+	reportUnusedIgnores bool
+	printDiff           bool
+	stagedMode          bool
 }
 
 func (l *linter) exit() error {
+	l.exitOnCrashes()
 	if l.foundIssues {
-		os.Exit(l.exitCode)
+		if code := l.exitCode.forSeverity(l.maxSeverity); code != 0 {
+			os.Exit(code)
+		}
 	}
 	return nil
 }
 
 func (l *linter) runCheckers() error {
 	for _, pkg := range l.loadedPackages {
+		if l.cache != nil {
+			if entry := l.cache.hits[pkg.ID]; entry != nil {
+				if err := l.replayPackage(pkg, entry); err != nil {
+					return fmt.Errorf("%s: %v", pkg, err)
+				}
+				continue
+			}
+			l.cache.startPackage()
+		}
 		if l.verbose {
 			log.Printf("\tdebug: checking %q package (%d files)",
 				pkg.String(), len(pkg.Syntax))
 		}
-		l.checkPackage(pkg)
+		crashes := len(l.crashes)
+		if err := l.checkPackage(pkg); err != nil {
+			return fmt.Errorf("%s: %v", pkg, err)
+		}
+		if l.cache != nil {
+			l.finishPackage(pkg, len(l.crashes) != crashes)
+		}
 	}
 
 	return nil
 }
 
-func (l *linter) checkPackage(pkg *packages.Package) {
+func (l *linter) checkPackage(pkg *packages.Package) error {
 	l.ctx.SetPackageInfo(pkg.TypesInfo, pkg.Types)
+	l.ctx.SetPackageFiles(pkg.Syntax)
+	checkers, err := l.checkersFor(pkg)
+	if err != nil {
+		return err
+	}
+	l.checkers = checkers
+	l.disabled = make(map[*lintpack.Checker]bool)
+	l.suppressions = make(map[*token.File][]*suppression)
+	l.pkgFiles = make(map[*token.File]*ast.File, len(pkg.Syntax))
 	for _, f := range pkg.Syntax {
-		filename := l.getFilename(f)
-		if !l.checkTests && strings.HasSuffix(filename, "_test.go") {
-			continue
+		l.pkgFiles[l.fset.File(f.Pos())] = f
+	}
+
+	// skipped records files that are excluded from checking.
+	// Package-level checkers see them, but their warnings are ignored.
+	skipped := make(map[*token.File]bool)
+
+	for _, f := range pkg.Syntax {
+		skip, err := l.isSkipped(f)
+		if err != nil {
+			return err
 		}
-		if !l.checkGenerated && l.isGenerated(f) {
+		if skip {
+			skipped[l.fset.File(f.Pos())] = true
 			continue
 		}
-		l.ctx.SetFileInfo(filename, f)
+		l.markChecked(l.fset.Position(f.Pos()).Filename)
+		l.collectSuppressions(f)
+		l.ctx.SetFileInfo(l.getFilename(f), f)
 		l.checkFile(f)
 	}
+
+	// Package crashes are reported for the first package file
+	// in the formats that describe issues per file.
+	pkgFilename := ""
+	if len(pkg.Syntax) != 0 {
+		pkgFilename = l.fset.Position(pkg.Syntax[0].Pos()).Filename
+	}
+	warnings := l.execCheckers(pkg.String(), pkgFilename, func(c *lintpack.Checker) []lintpack.Warning {
+		return c.CheckPackage(pkg.Syntax)
+	})
+	for i := range warnings {
+		filtered := warnings[i][:0]
+		for _, warn := range warnings[i] {
+			if !skipped[l.fset.File(warn.Node.Pos())] {
+				filtered = append(filtered, warn)
+			}
+		}
+		warnings[i] = filtered
+	}
+	l.reportWarnings(warnings)
+
+	if l.reportUnusedIgnores {
+		l.reportUnusedSuppressions(pkg.Syntax)
+	}
+	return nil
+}
+
+// isSkipped reports whether the file is not checked because of
+// -checkTests, -checkGenerated or the config file excludes.
+func (l *linter) isSkipped(f *ast.File) (bool, error) {
+	if !l.checkTests && strings.HasSuffix(l.getFilename(f), "_test.go") ||
+		!l.checkGenerated && l.isGenerated(f) {
+		return true, nil
+	}
+	return l.isExcluded(l.fset.Position(f.Pos()).Filename)
+}
+
+// markChecked records that the file warnings are reported.
+func (l *linter) markChecked(filename string) {
+	if l.baseline != nil {
+		l.baseline.checkedFiles[l.baseline.relPath(filename)] = true
+	}
+	if l.isCacheable() {
+		l.cache.current.Files = append(l.cache.current.Files, filename)
+	}
 }
 
 func (l *linter) checkFile(f *ast.File) {
+	location := l.ctx.FileSet.Position(f.Pos()).Filename
+	l.reportWarnings(l.execCheckers(location, location, func(c *lintpack.Checker) []lintpack.Warning {
+		return c.Check(f)
+	}))
+}
+
+// execCheckers executes check function for every checker concurrently.
+// Returned warnings are indexed in the same way as l.checkers.
+//
+// Checkers that panic are disabled for the rest of the package.
+// location describes a file or package being checked for the crash reports,
+// filename is a file the crashes are attributed to.
+func (l *linter) execCheckers(location, filename string, check func(c *lintpack.Checker) []lintpack.Warning) [][]lintpack.Warning {
 	warnings := make([][]lintpack.Warning, len(l.checkers))
+	crashes := make([]*checkerCrash, len(l.checkers))
 
 	var wg sync.WaitGroup
-	wg.Add(len(l.checkers))
 	for i, c := range l.checkers {
+		if l.isDisabled(c) {
+			continue
+		}
+		wg.Add(1)
 		// All checkers are expected to use *lint.Context
 		// as read-only structure, so no copying is required.
 		go func(i int, c *lintpack.Checker) {
+			defer wg.Done()
 			defer func() {
-				wg.Done()
 				// Checker signals unexpected error with panic(error).
+				// Other kinds of run-time panics are reported in the same way.
 				r := recover()
 				if r == nil {
 					return // There were no panic
 				}
-				if err, ok := r.(error); ok {
-					log.Printf("%s: error: %v\n", c.Info.Name, err)
-					panic(err)
-				} else {
-					// Some other kind of run-time panic.
-					// Undo the recover and resume panic.
-					panic(r)
+				warnings[i] = nil
+				crashes[i] = &checkerCrash{
+					checker:  c.Info.Name,
+					location: location,
+					filename: filename,
+					value:    r,
+					stack:    debug.Stack(),
 				}
 			}()
 
-			for _, warn := range c.Check(f) {
+			for _, warn := range check(c) {
 				warnings[i] = append(warnings[i], warn)
 			}
 		}(i, c)
 	}
 	wg.Wait()
 
+	l.handleCrashes(crashes)
+	return warnings
+}
+
+func (l *linter) reportWarnings(warnings [][]lintpack.Warning) {
 	for i, c := range l.checkers {
 		for _, warn := range warnings[i] {
-			l.foundIssues = true
-			loc := l.ctx.FileSet.Position(warn.Node.Pos()).String()
-			if l.shorterErrLocation {
-				loc = l.shortenLocation(loc)
+			if !l.isSuppressed(c.Info, warn) {
+				l.report(c.Info, warn)
 			}
-			printWarning(l, c.Info.Name, loc, warn.Text)
 		}
 	}
-
 }
 
-func (l *linter) initCheckers() error {
-	parseKeys := func(keys []string, byName, byTag map[string]bool) {
-		for _, key := range keys {
-			if strings.HasPrefix(key, "#") {
-				byTag[key[len("#"):]] = true
-			} else {
-				byName[key] = true
-			}
-		}
+// report passes the warning to the reporter unless
+// it's less serious than -minSeverity, accepted by the baseline
+// or located outside of the -staged files or -diffBase/-diffFile changes.
+//
+// Warnings are recorded for the cache before they are filtered.
+func (l *linter) report(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	if l.isCacheable() {
+		l.recordWarning(info, warn)
 	}
-
-	enabledByName := make(map[string]bool)
-	enabledTags := make(map[string]bool)
-	parseKeys(l.filters.enable, enabledByName, enabledTags)
-	disabledByName := make(map[string]bool)
-	disabledTags := make(map[string]bool)
-	parseKeys(l.filters.disable, disabledByName, disabledTags)
-
-	enabledByTag := func(info *lintpack.CheckerInfo) bool {
-		for _, tag := range info.Tags {
-			if enabledTags[tag] {
-				return true
-			}
-		}
-		return false
+	// Baseline is checked before the diff, so baseline warnings
+	// outside of the diff are not considered fixed.
+	if warn.Severity < l.minSeverity || !l.isStaged(warn) ||
+		l.isBaselined(info, warn) || !l.isChanged(warn) {
+		return
 	}
-	disabledByTag := func(info *lintpack.CheckerInfo) string {
-		for _, tag := range info.Tags {
-			if disabledTags[tag] {
-				return tag
-			}
-		}
-		return ""
+	l.foundIssues = true
+	if warn.Severity > l.maxSeverity {
+		l.maxSeverity = warn.Severity
 	}
+	l.reporter.Warn(info, warn)
+	l.addFix(info.Name, warn.Fix)
+}
+
+func (l *linter) initCheckers() error {
+	enableFilter := newCheckerFilter(l.filters.enable)
+	disableFilter := newCheckerFilter(l.filters.disable)
 
 	for _, info := range l.infoList {
 		enabled := l.filters.enableAll ||
-			enabledByName[info.Name] ||
-			enabledByTag(info)
+			enableFilter.names[info.Name] ||
+			enableFilter.matchTag(info) != ""
 		notice := ""
 
 		switch {
 		case !enabled:
 			notice = "not enabled by name or tag (-enable)"
-		case disabledByName[info.Name]:
+		case disableFilter.names[info.Name]:
 			enabled = false
 			notice = "disabled by name (-disable)"
 		default:
-			if tag := disabledByTag(info); tag != "" {
+			if tag := disableFilter.matchTag(info); tag != "" {
 				enabled = false
 				notice = fmt.Sprintf("disabled by %q tag (-disable)", tag)
 			}
@@ -237,9 +434,19 @@ func (l *linter) initCheckers() error {
 	if len(l.checkers) == 0 {
 		return errors.New("empty checkers set selected")
 	}
+	l.baseCheckers = l.checkers
+	if err := l.initOverrides(); err != nil {
+		return err
+	}
+	l.reporter = reporters[l.format](l)
 	return nil
 }
 
+// printReport finishes the report of the selected output format.
+func (l *linter) printReport() error {
+	return l.reporter.Finish()
+}
+
 func (l *linter) loadProgram() error {
 	sizes := types.SizesFor("gc", runtime.GOARCH)
 	if sizes == nil {
@@ -252,22 +459,93 @@ func (l *linter) loadProgram() error {
 		Tests: true,
 		Fset:  l.fset,
 	}
+	if l.stagedMode {
+		staged, err := loadStagedSources()
+		if err != nil {
+			return fmt.Errorf("-staged: %v", err)
+		}
+		if l.verbose {
+			log.Printf("\tdebug: %d staged files, %d files are read from the git index",
+				len(staged.files), len(staged.overlay))
+		}
+		l.staged = staged
+		l.overlay = staged.overlay
+		cfg.Overlay = staged.overlay
+	}
+	if l.cache != nil {
+		// Syntax is loaded by loadCachedResults for
+		// the packages that are not cached.
+		// Dependencies are loaded from their export data,
+		// that is used to compute the cache keys.
+		cfg.Mode = packages.LoadTypes
+	}
+	l.loadConfig = &cfg
 	pkgs, err := loadPackages(&cfg, l.packages)
 	if err != nil {
 		log.Fatalf("load packages: %v", err)
 	}
+	l.loadedPackages = sortPackages(pkgs)
+	l.ctx = lintpack.NewContext(l.fset, sizes)
+
+	return nil
+}
+
+// sortPackages orders pkgs in a way that every package comes after
+// all its dependencies, so facts exported by checkers for the dependencies
+// are available when dependent packages are checked.
+// Packages that don't depend on each other are ordered by their path.
+func sortPackages(pkgs []*packages.Package) []*packages.Package {
 	sort.SliceStable(pkgs, func(i, j int) bool {
 		return pkgs[i].PkgPath < pkgs[j].PkgPath
 	})
 
-	l.loadedPackages = pkgs
-	l.ctx = lintpack.NewContext(l.fset, sizes)
+	// Dependent packages import the base package while
+	// its test variant may be checked instead of it.
+	// Hence, selected packages are also matched by their path.
+	selected := make(map[*packages.Package]bool, len(pkgs))
+	selectedByPath := make(map[string][]*packages.Package)
+	for _, pkg := range pkgs {
+		selected[pkg] = true
+		selectedByPath[pkg.PkgPath] = append(selectedByPath[pkg.PkgPath], pkg)
+	}
 
-	return nil
+	sorted := make([]*packages.Package, 0, len(pkgs))
+	visited := make(map[*packages.Package]bool)
+	var visit func(pkg *packages.Package)
+	visit = func(pkg *packages.Package) {
+		if visited[pkg] {
+			return
+		}
+		visited[pkg] = true
+
+		paths := make([]string, 0, len(pkg.Imports))
+		for path := range pkg.Imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			dep := pkg.Imports[path]
+			visit(dep)
+			for _, variant := range selectedByPath[dep.PkgPath] {
+				visit(variant)
+			}
+		}
+
+		if selected[pkg] {
+			sorted = append(sorted, pkg)
+		}
+	}
+	for _, pkg := range pkgs {
+		visit(pkg)
+	}
+
+	return sorted
 }
 
+// pluginFilename is a checkers plugin that is loaded if present.
+const pluginFilename = "lintpack-plugin.so"
+
 func (l *linter) loadPlugin() error {
-	const pluginFilename = "lintpack-plugin.so"
 	if _, err := os.Stat(pluginFilename); os.IsNotExist(err) {
 		return nil
 	}
@@ -276,43 +554,21 @@ func (l *linter) loadPlugin() error {
 	return err
 }
 
-type boundCheckerParams struct {
-	ints    map[string]*int
-	bools   map[string]*bool
-	strings map[string]*string
-}
-
 // bindCheckerParams registers command-line flags for every checker parameter.
+//
+// Flags update parameter values directly, parsed values are validated
+// against the parameter constraints during the flags parsing.
 func (l *linter) bindCheckerParams() error {
-	intParams := make(map[string]*int)
-	boolParams := make(map[string]*bool)
-	stringParams := make(map[string]*string)
-
 	for _, info := range l.infoList {
 		for pname, param := range info.Params {
-			key := l.checkerParamKey(info, pname)
-			switch v := param.Value.(type) {
-			case int:
-				intParams[key] = flag.Int(key, v, param.Usage)
-			case bool:
-				boolParams[key] = flag.Bool(key, v, param.Usage)
-			case string:
-				stringParams[key] = flag.String(key, v, param.Usage)
-			default:
-				panic("unreachable") // Checked in AddChecker
-			}
+			flag.Var(param, l.checkerParamKey(info.Name, pname), param.Usage)
 		}
 	}
-
-	l.checkerParams.ints = intParams
-	l.checkerParams.bools = boolParams
-	l.checkerParams.strings = stringParams
-
 	return nil
 }
 
-func (l *linter) checkerParamKey(info *lintpack.CheckerInfo, pname string) string {
-	return "@" + info.Name + "." + pname
+func (l *linter) checkerParamKey(checker, pname string) string {
+	return "@" + checker + "." + pname
 }
 
 // bindDefaultEnabledList calculates the default value for -enable param.
@@ -337,23 +593,141 @@ func (l *linter) parseArgs() error {
 		`comma-separated list of enabled checkers. Can include #tags`)
 	disable := flag.String("disable", "",
 		`comma-separated list of checkers to be disabled. Can include #tags`)
-	flag.IntVar(&l.exitCode, "exitCode", 1,
-		`exit code to be used when lint issues are found`)
+	l.exitCode.defaultCode = 1
+	flag.Var(&l.exitCode, "exitCode",
+		`exit code to be used when lint issues are found. `+
+			`Can be specified per severity, like "error=2,warning=1,0"`)
+	flag.StringVar(&l.format, "format", "text",
+		`output format: `+strings.Join(reporterNames(), ", ")+`. `+
+			`Custom format is set by template:TEXT, like "template:{{.File}}:{{.Line}}: {{.Text}}". `+
+			`Template fields: `+strings.Join(templateFields(), ", "))
+	formatFile := flag.String("formatFile", "",
+		`file with a custom output template, an alternative to -format=template:TEXT. `+
+			`The template has the same fields as -format templates`)
+	minSeverity := flag.String("minSeverity", "hint",
+		`the least serious severity of reported warnings (hint, info, warning or error)`)
 	flag.BoolVar(&l.checkTests, "checkTests", true,
 		`whether to check test files`)
+	flag.BoolVar(&l.checkGenerated, "checkGenerated", false,
+		`whether to check generated files`)
+	configFile := flag.String("config", "",
+		`config file path. If empty, `+strings.Join(configFilenames(l.name), ", ")+
+			` is searched in the working directory and its parents`)
 	flag.BoolVar(&l.shorterErrLocation, `shorterErrLocation`, true,
 		`whether to replace error location prefix with $GOROOT and $GOPATH`)
 	flag.BoolVar(&l.coloredOutput, `coloredOutput`, false,
 		`whether to use colored output`)
 	flag.BoolVar(&l.verbose, "v", false,
 		`whether to print output useful during linter debugging`)
+	flag.BoolVar(&l.requireIgnoreReason, "requireIgnoreReason", false,
+		`whether to require a reason in //lintpack:ignore directives`)
+	flag.BoolVar(&l.reportUnusedIgnores, "reportUnusedIgnores", false,
+		`whether to report //lintpack:ignore directives that suppress nothing`)
+	baselineFile := flag.String("baseline", "",
+		`baseline file written by -writeBaseline. Only warnings that are not in the baseline are reported`)
+	flag.StringVar(&l.writeBaselineFile, "writeBaseline", "",
+		`file to record all current warnings to, instead of reporting them`)
+	diffBase := flag.String("diffBase", "",
+		`git revision to compare the working tree with. Only warnings on added or modified lines are reported`)
+	diffFile := flag.String("diffFile", "",
+		`unified diff file, an alternative to -diffBase. File names are relative to the working directory`)
+	flag.BoolVar(&l.stagedMode, "staged", false,
+		`whether to check the git index contents and only report warnings in staged files`)
+	useCache := flag.Bool("cache", true,
+		`whether to reuse results of the unchanged packages from the previous runs`)
+	flag.BoolVar(&l.fix, "fix", false,
+		`whether to apply suggested fixes by rewriting files in place`)
+	flag.BoolVar(&l.printDiff, "diff", false,
+		`whether to print suggested fixes as a unified diff`)
 
 	flag.Parse()
 
+	if err := l.applyConfig(*configFile); err != nil {
+		return err
+	}
+
 	l.packages = flag.Args()
 	l.filters.enable = strings.Split(*enable, ",")
 	l.filters.disable = strings.Split(*disable, ",")
 
+	if *formatFile != "" {
+		data, err := ioutil.ReadFile(*formatFile)
+		if err != nil {
+			return fmt.Errorf("-formatFile: %v", err)
+		}
+		l.format = "template:" + string(data)
+	}
+	if strings.HasPrefix(l.format, "template:") {
+		tmpl, err := parseOutputTemplate(strings.TrimPrefix(l.format, "template:"))
+		if err != nil {
+			return fmt.Errorf("-format: %v", err)
+		}
+		l.format = "template"
+		l.formatTemplate = tmpl
+	}
+	switch {
+	case reporters[l.format] == nil:
+		return fmt.Errorf("-format: unknown %q format", l.format)
+	case l.format == "template" && l.formatTemplate == nil:
+		return errors.New("-format: template format requires template:TEXT or -formatFile")
+	case l.printDiff && l.format != "text":
+		// The diff would be printed after the report document.
+		return fmt.Errorf("-diff can't be used with %s format", l.format)
+	}
+
+	switch {
+	case *baselineFile != "" && l.writeBaselineFile != "":
+		return errors.New("-baseline and -writeBaseline can't be used together")
+	case *baselineFile != "":
+		b, err := loadBaseline(*baselineFile)
+		if err != nil {
+			return fmt.Errorf("-baseline: %v", err)
+		}
+		l.baseline = b
+	case l.writeBaselineFile != "":
+		b, err := newBaseline(l.writeBaselineFile)
+		if err != nil {
+			return fmt.Errorf("-writeBaseline: %v", err)
+		}
+		l.baseline = b
+	}
+
+	if l.stagedMode && l.fix {
+		// Fixes are computed for the index contents,
+		// so they can't be applied to the working tree files.
+		return errors.New("-staged and -fix can't be used together")
+	}
+
+	switch {
+	case *diffBase != "" && *diffFile != "":
+		return errors.New("-diffBase and -diffFile can't be used together")
+	case *diffBase != "":
+		changed, err := loadGitDiff(*diffBase)
+		if err != nil {
+			return fmt.Errorf("-diffBase: %v", err)
+		}
+		l.changedLines = changed
+	case *diffFile != "":
+		changed, err := loadDiffFile(*diffFile)
+		if err != nil {
+			return fmt.Errorf("-diffFile: %v", err)
+		}
+		l.changedLines = changed
+	}
+
+	if *useCache {
+		l.cache = newResultCache(l.name)
+		if l.cache == nil && l.verbose {
+			log.Printf("\tdebug: no user cache directory, caching is disabled")
+		}
+	}
+
+	sev, err := lintpack.ParseSeverity(*minSeverity)
+	if err != nil {
+		return fmt.Errorf("-minSeverity: %v", err)
+	}
+	l.minSeverity = sev
+
 	if l.shorterErrLocation {
 		wd, err := os.Getwd()
 		if err != nil {
@@ -374,32 +748,6 @@ func addTrailingSlash(s string) string {
 	return s + string(os.PathSeparator)
 }
 
-// assignCheckerParams initializes checker parameter values using
-// values that are coming from the command-line arguments.
-func (l *linter) assignCheckerParams() error {
-	intParams := l.checkerParams.ints
-	boolParams := l.checkerParams.bools
-	stringParams := l.checkerParams.strings
-
-	for _, info := range l.infoList {
-		for pname, param := range info.Params {
-			key := l.checkerParamKey(info, pname)
-			switch param.Value.(type) {
-			case int:
-				info.Params[pname].Value = *intParams[key]
-			case bool:
-				info.Params[pname].Value = *boolParams[key]
-			case string:
-				info.Params[pname].Value = *stringParams[key]
-			default:
-				panic("unreachable") // Checked in AddChecker
-			}
-		}
-	}
-
-	return nil
-}
-
 var generatedFileCommentRE = regexp.MustCompile("Code generated .* DO NOT EDIT.")
 
 func (l *linter) isGenerated(f *ast.File) bool {
@@ -433,17 +781,60 @@ func (l *linter) shortenLocation(loc string) string {
 	return loc
 }
 
-func printWarning(l *linter, rule, loc, warn string) {
-	switch {
-	case l.coloredOutput:
-		log.Printf("%v: %v: %v\n",
-			aurora.Magenta(aurora.Bold(loc)),
-			aurora.Red(rule),
-			warn)
+// exitCodes maps the most serious reported severity to the linter exit code.
+type exitCodes struct {
+	// defaultCode is used for severities without explicit exit code.
+	defaultCode int
+
+	bySeverity map[lintpack.Severity]int
+}
 
-	default:
-		log.Printf("%s: %s: %s\n", loc, rule, warn)
+func (codes *exitCodes) forSeverity(s lintpack.Severity) int {
+	if code, ok := codes.bySeverity[s]; ok {
+		return code
 	}
+	return codes.defaultCode
+}
+
+// String implements flag.Value interface.
+func (codes *exitCodes) String() string {
+	parts := []string{strconv.Itoa(codes.defaultCode)}
+	for s := lintpack.SeverityError; s >= lintpack.SeverityHint; s-- {
+		if code, ok := codes.bySeverity[s]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", s, code))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value interface.
+//
+// Accepts a comma-separated list of severity=code pairs
+// and at most one code without severity that is used as a default.
+func (codes *exitCodes) Set(s string) error {
+	codes.bySeverity = make(map[lintpack.Severity]int)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		eq := strings.IndexByte(part, '=')
+		if eq == -1 {
+			code, err := strconv.Atoi(part)
+			if err != nil {
+				return err
+			}
+			codes.defaultCode = code
+			continue
+		}
+		sev, err := lintpack.ParseSeverity(part[:eq])
+		if err != nil {
+			return err
+		}
+		code, err := strconv.Atoi(part[eq+1:])
+		if err != nil {
+			return err
+		}
+		codes.bySeverity[sev] = code
+	}
+	return nil
 }
 
 func loadPackages(cfg *packages.Config, patterns []string) ([]*packages.Package, error) {
@@ -1,7 +1,18 @@
 package check
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+
+	"github.com/go-lintpack/lintpack"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestShortenLocation(t *testing.T) {
@@ -39,3 +50,144 @@ func TestShortenLocation(t *testing.T) {
 		}
 	}
 }
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		flag string
+		want map[lintpack.Severity]int
+	}{
+		{"1", map[lintpack.Severity]int{
+			lintpack.SeverityHint:  1,
+			lintpack.SeverityError: 1,
+		}},
+		{"error=2,warning=1,0", map[lintpack.Severity]int{
+			lintpack.SeverityHint:    0,
+			lintpack.SeverityInfo:    0,
+			lintpack.SeverityWarning: 1,
+			lintpack.SeverityError:   2,
+		}},
+		{"error=3", map[lintpack.Severity]int{
+			lintpack.SeverityInfo:  1,
+			lintpack.SeverityError: 3,
+		}},
+	}
+
+	for _, test := range tests {
+		codes := exitCodes{defaultCode: 1}
+		if err := codes.Set(test.flag); err != nil {
+			t.Errorf("set %q: %v", test.flag, err)
+			continue
+		}
+		for sev, want := range test.want {
+			if have := codes.forSeverity(sev); have != want {
+				t.Errorf("%q: %s: have %d, want %d", test.flag, sev, have, want)
+			}
+		}
+	}
+
+	var codes exitCodes
+	if err := codes.Set("fatal=2"); err == nil {
+		t.Errorf("expected error for unknown severity")
+	}
+}
+
+func TestSortPackages(t *testing.T) {
+	newPackage := func(id, path string, imports ...*packages.Package) *packages.Package {
+		pkg := &packages.Package{
+			ID:      id,
+			PkgPath: path,
+			Imports: make(map[string]*packages.Package),
+		}
+		for _, imp := range imports {
+			pkg.Imports[imp.PkgPath] = imp
+		}
+		return pkg
+	}
+
+	fmtPkg := newPackage("fmt", "fmt")
+	c := newPackage("example.com/c", "example.com/c", fmtPkg)
+	b := newPackage("example.com/b", "example.com/b", c)
+	bTest := newPackage("example.com/b [example.com/b.test]", "example.com/b", c)
+	a := newPackage("example.com/a", "example.com/a", b, fmtPkg)
+	z := newPackage("example.com/z", "example.com/z")
+
+	// a depends on b, but b test variant is checked instead of b.
+	sorted := sortPackages([]*packages.Package{z, a, bTest, c})
+
+	var have []string
+	for _, pkg := range sorted {
+		have = append(have, pkg.ID)
+	}
+	want := []string{
+		"example.com/c",
+		"example.com/b [example.com/b.test]",
+		"example.com/a",
+		"example.com/z",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("sorted packages mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+}
+
+// newTestLinter returns a linter with all checkers of the collection
+// enabled. Warnings are collected by the returned reporter.
+func newTestLinter(coll *lintpack.CheckerCollection) (*linter, *collectReporter) {
+	l := &linter{fset: token.NewFileSet(), checkTests: true}
+	l.ctx = lintpack.NewContext(l.fset, nil)
+	for _, info := range coll.Registry.GetCheckersInfo() {
+		l.checkers = append(l.checkers, coll.Registry.NewChecker(l.ctx, info))
+	}
+	l.baseCheckers = l.checkers
+	r := &collectReporter{l: l}
+	l.reporter = r
+	return l, r
+}
+
+// checkTestPackage writes the package files into dir and checks them.
+// Types are not checked. Returns the reported warnings with
+// file names that are relative to dir, sorted by their position.
+func checkTestPackage(t *testing.T, l *linter, r *collectReporter, dir string, files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pkg := &packages.Package{ID: "example.com/a", PkgPath: "example.com/a"}
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := parser.ParseFile(l.fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg.GoFiles = append(pkg.GoFiles, filename)
+		pkg.Syntax = append(pkg.Syntax, f)
+	}
+	if err := l.checkPackage(pkg); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := make([]string, len(r.warnings))
+	for i, warn := range r.warnings {
+		warnings[i] = strings.TrimPrefix(warn, dir+string(filepath.Separator))
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// callWalker reports every call expression.
+type callWalker struct {
+	ctx *lintpack.CheckerContext
+}
+
+func (w *callWalker) WalkFile(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			w.ctx.Warn(call, "call of %s", call.Fun.(*ast.Ident).Name)
+		}
+		return true
+	})
+}
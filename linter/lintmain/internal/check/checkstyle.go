@@ -0,0 +1,98 @@
+package check
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// checkstyleReport collects warnings for the checkstyle output format.
+// The format is understood by Jenkins and other CI systems.
+type checkstyleReport struct {
+	l *linter
+
+	// files maps file names to their warnings.
+	files map[string][]checkstyleError
+}
+
+type checkstyleDocument struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func newCheckstyleReport(l *linter) reporter {
+	return &checkstyleReport{
+		l:     l,
+		files: make(map[string][]checkstyleError),
+	}
+}
+
+func (r *checkstyleReport) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	pos := r.l.fset.Position(warn.Node.Pos())
+	r.files[pos.Filename] = append(r.files[pos.Filename], checkstyleError{
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Severity: checkstyleSeverity(warn.Severity),
+		Message:  warn.Text,
+		Source:   r.l.name + "." + info.Name,
+	})
+}
+
+func (r *checkstyleReport) Finish() error {
+	for _, crash := range r.l.crashes {
+		r.files[crash.filename] = append(r.files[crash.filename], checkstyleError{
+			Line:     1,
+			Column:   1,
+			Severity: "error",
+			Message:  fmt.Sprintf("%s: internal checker error: %v", crash.location, crash.value),
+			Source:   r.l.name + "." + crash.checker,
+		})
+	}
+
+	doc := checkstyleDocument{Version: "5.0"}
+	for name, errors := range r.files {
+		doc.Files = append(doc.Files, checkstyleFile{Name: name, Errors: errors})
+	}
+	sort.Slice(doc.Files, func(i, j int) bool {
+		return doc.Files[i].Name < doc.Files[j].Name
+	})
+	return printXML(r.l.out, doc)
+}
+
+func checkstyleSeverity(s lintpack.Severity) string {
+	switch s {
+	case lintpack.SeverityError:
+		return "error"
+	case lintpack.SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// printXML prints v as an indented XML document to w.
+func printXML(w io.Writer, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
@@ -0,0 +1,115 @@
+package check
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// codeClimateReport collects warnings for the codeclimate output format.
+// The format is understood by GitLab code quality reports.
+type codeClimateReport struct {
+	l *linter
+
+	issues []codeClimateIssue
+
+	// seen counts issues with the same fingerprint base.
+	seen map[string]int
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+func newCodeClimateReport(l *linter) reporter {
+	return &codeClimateReport{
+		l:      l,
+		issues: []codeClimateIssue{},
+		seen:   make(map[string]int),
+	}
+}
+
+func (r *codeClimateReport) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	pos := r.l.fset.Position(warn.Node.Pos())
+	end := r.l.fset.Position(warn.Node.End())
+	path := relativePath(pos.Filename)
+
+	r.issues = append(r.issues, codeClimateIssue{
+		Type:        "issue",
+		CheckName:   info.Name,
+		Description: warn.Text,
+		Categories:  []string{"Style"},
+		Severity:    codeClimateSeverity(warn.Severity),
+		Fingerprint: r.fingerprint(path, info.Name, warn.Text),
+		Location: codeClimateLocation{
+			Path:  path,
+			Lines: codeClimateLines{Begin: pos.Line, End: end.Line},
+		},
+	})
+}
+
+// fingerprint returns an issue identifier that doesn't depend on
+// the issue line, so it survives unrelated code changes.
+// Identical issues inside the same file are told apart by their order.
+func (r *codeClimateReport) fingerprint(path, checker, text string) string {
+	base := path + "\x00" + checker + "\x00" + text
+	n := r.seen[base]
+	r.seen[base]++
+	sum := md5.Sum([]byte(fmt.Sprintf("%s\x00%d", base, n)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *codeClimateReport) Finish() error {
+	for _, crash := range r.l.crashes {
+		path := relativePath(crash.filename)
+		// Locations are relative, so fingerprints don't
+		// depend on the checkout directory.
+		text := fmt.Sprintf("%s: internal checker error: %v", relativePath(crash.location), crash.value)
+		r.issues = append(r.issues, codeClimateIssue{
+			Type:        "issue",
+			CheckName:   crash.checker,
+			Description: text,
+			Categories:  []string{"Bug Risk"},
+			Severity:    "critical",
+			Fingerprint: r.fingerprint(path, crash.checker, text),
+			Location: codeClimateLocation{
+				Path:  path,
+				Lines: codeClimateLines{Begin: 1, End: 1},
+			},
+		})
+	}
+
+	enc := json.NewEncoder(r.l.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.issues)
+}
+
+func codeClimateSeverity(s lintpack.Severity) string {
+	switch s {
+	case lintpack.SeverityError:
+		return "major"
+	case lintpack.SeverityWarning:
+		return "minor"
+	default:
+		return "info"
+	}
+}
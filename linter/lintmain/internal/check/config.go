@@ -0,0 +1,334 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// config is a project configuration file contents.
+//
+// Every setting, except Exclude, has a corresponding command-line flag.
+// Flags that are set explicitly override the config file settings.
+type config struct {
+	// Enable is a list of enabled checkers. Can include #tags.
+	Enable []string `json:"enable" yaml:"enable"`
+
+	// Disable is a list of disabled checkers. Can include #tags.
+	Disable []string `json:"disable" yaml:"disable"`
+
+	// EnableAll enables all checkers; Enable is ignored if set.
+	EnableAll *bool `json:"enableAll" yaml:"enableAll"`
+
+	// CheckTests controls whether test files are checked.
+	CheckTests *bool `json:"checkTests" yaml:"checkTests"`
+
+	// CheckGenerated controls whether generated files are checked.
+	CheckGenerated *bool `json:"checkGenerated" yaml:"checkGenerated"`
+
+	// MinSeverity is the least serious severity of reported warnings.
+	MinSeverity string `json:"minSeverity" yaml:"minSeverity"`
+
+	// Params maps checker names to their parameter values.
+	// Lists are permitted for []string params.
+	Params map[string]map[string]interface{} `json:"params" yaml:"params"`
+
+	// Exclude is a list of file path patterns that are not checked.
+	// Patterns are matched against slash-separated paths relative
+	// to the config file directory; patterns without slashes
+	// are matched against file base names.
+	// Trailing "/**" matches all files under the directory.
+	Exclude []string `json:"exclude" yaml:"exclude"`
+
+	// Overrides change checker settings for specific directories.
+	Overrides []configOverride `json:"overrides" yaml:"overrides"`
+}
+
+// configOverride changes enabled checkers and their params
+// for the packages inside the specified directories.
+//
+// Overrides are applied in order, on top of the global settings.
+type configOverride struct {
+	// Paths is a list of directory patterns, relative to the config file.
+	// Override applies to the matching directories and all their subdirectories.
+	Paths []string `json:"paths" yaml:"paths"`
+
+	// Enable is a list of additionally enabled checkers. Can include #tags.
+	Enable []string `json:"enable" yaml:"enable"`
+
+	// Disable is a list of disabled checkers. Can include #tags.
+	// Disable takes precedence over Enable.
+	Disable []string `json:"disable" yaml:"disable"`
+
+	// Params maps checker names to their parameter values.
+	Params map[string]map[string]interface{} `json:"params" yaml:"params"`
+}
+
+// configFlag is a config setting expressed as a command-line flag value.
+type configFlag struct {
+	name  string
+	value string
+}
+
+// configFilenames returns config file names that are
+// searched for the linter with the given name.
+func configFilenames(linterName string) []string {
+	base := "." + linterName
+	return []string{base + ".yml", base + ".yaml", base + ".json"}
+}
+
+// findConfigFile searches for the linter config file inside dir
+// and all its parents. Returns empty string if nothing is found.
+func findConfigFile(linterName, dir string) string {
+	for {
+		for _, name := range configFilenames(linterName) {
+			filename := filepath.Join(dir, name)
+			if _, err := os.Stat(filename); err == nil {
+				return filename
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads YAML or JSON config file, depending on its extension.
+// Unknown settings are reported as errors.
+func loadConfig(filename string) (*config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config
+	if filepath.Ext(filename) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	} else {
+		err = yaml.UnmarshalStrict(data, &cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	for _, pattern := range cfg.Exclude {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			return nil, fmt.Errorf("%s: exclude %q: %v", filename, pattern, err)
+		}
+	}
+	for i, o := range cfg.Overrides {
+		if len(o.Paths) == 0 {
+			return nil, fmt.Errorf("%s: override #%d: empty paths list", filename, i)
+		}
+		for _, pattern := range o.Paths {
+			if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+				return nil, fmt.Errorf("%s: override #%d: path %q: %v", filename, i, pattern, err)
+			}
+		}
+	}
+	return &cfg, nil
+}
+
+// flags returns config settings as flag values.
+// paramKey maps checker param to its flag name.
+func (cfg *config) flags(paramKey func(checker, param string) string) []configFlag {
+	var flags []configFlag
+	add := func(name, value string) {
+		flags = append(flags, configFlag{name: name, value: value})
+	}
+	addBool := func(name string, value *bool) {
+		if value != nil {
+			add(name, fmt.Sprint(*value))
+		}
+	}
+
+	if cfg.Enable != nil {
+		add("enable", strings.Join(cfg.Enable, ","))
+	}
+	if cfg.Disable != nil {
+		add("disable", strings.Join(cfg.Disable, ","))
+	}
+	addBool("enableAll", cfg.EnableAll)
+	addBool("checkTests", cfg.CheckTests)
+	addBool("checkGenerated", cfg.CheckGenerated)
+	if cfg.MinSeverity != "" {
+		add("minSeverity", cfg.MinSeverity)
+	}
+
+	checkers := make([]string, 0, len(cfg.Params))
+	for checker := range cfg.Params {
+		checkers = append(checkers, checker)
+	}
+	sort.Strings(checkers)
+	for _, checker := range checkers {
+		params := cfg.Params[checker]
+		pnames := make([]string, 0, len(params))
+		for pname := range params {
+			pnames = append(pnames, pname)
+		}
+		sort.Strings(pnames)
+		for _, pname := range pnames {
+			add(paramKey(checker, pname), configValueString(params[pname]))
+		}
+	}
+
+	return flags
+}
+
+// matchDirPattern reports whether the slash-separated relative
+// directory path or any of its parents matches the pattern.
+// Trailing "/**" in the pattern is ignored.
+// The root directory "." is only matched by "." and "**" patterns,
+// which match all its subdirectories as well.
+func matchDirPattern(pattern, dir string) bool {
+	pattern = strings.TrimSuffix(pattern, "/**")
+	if pattern == "." || pattern == "**" {
+		return true
+	}
+	for ; dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+		if ok, _ := path.Match(pattern, dir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// configValueString converts a decoded config value to
+// a string that can be parsed by the flag.Value.
+func configValueString(v interface{}) string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return configScalarString(v)
+	}
+	parts := make([]string, len(list))
+	for i, x := range list {
+		parts[i] = configScalarString(x)
+	}
+	return strings.Join(parts, ",")
+}
+
+// configScalarString is like fmt.Sprint, but formats floats
+// without exponents, since all JSON numbers are decoded as floats.
+func configScalarString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// matchFilePattern reports whether the slash-separated relative
+// file path matches the exclude pattern.
+func matchFilePattern(pattern, filename string) bool {
+	if dir := strings.TrimSuffix(pattern, "/**"); dir != pattern {
+		if dir == "." || dir == "**" {
+			return true
+		}
+		for d := path.Dir(filename); d != "." && d != "/"; d = path.Dir(d) {
+			if ok, _ := path.Match(dir, d); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		filename = path.Base(filename)
+	}
+	ok, _ := path.Match(pattern, filename)
+	return ok
+}
+
+// applyConfig loads the config file and applies its settings
+// that were not overridden by the command-line flags.
+//
+// Must be called after flag.Parse.
+func (l *linter) applyConfig(configFile string) error {
+	if configFile == "" && l.name != "" {
+		if wd, err := os.Getwd(); err == nil {
+			configFile = findConfigFile(l.name, wd)
+		}
+	}
+
+	l.settingSources = make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		l.settingSources[f.Name] = "command line"
+	})
+
+	if configFile != "" {
+		cfg, err := loadConfig(configFile)
+		if err != nil {
+			return err
+		}
+		for _, f := range cfg.flags(l.checkerParamKey) {
+			if _, ok := l.settingSources[f.name]; ok {
+				continue // Overridden by the command-line flag
+			}
+			if flag.Lookup(f.name) == nil {
+				return fmt.Errorf("%s: unknown %s setting", configFile, f.name)
+			}
+			if err := flag.Set(f.name, f.value); err != nil {
+				return fmt.Errorf("%s: %s: %v", configFile, f.name, err)
+			}
+			l.settingSources[f.name] = configFile
+		}
+		// Files are matched by their absolute paths, so the
+		// config directory must be absolute as well.
+		dir, err := filepath.Abs(filepath.Dir(configFile))
+		if err != nil {
+			return err
+		}
+		l.configDir = dir
+		l.excludes = cfg.Exclude
+		l.configOverrides = cfg.Overrides
+	}
+
+	if l.verbose {
+		if configFile != "" {
+			log.Printf("\tdebug: using %s config file", configFile)
+		}
+		flag.VisitAll(func(f *flag.Flag) {
+			source, ok := l.settingSources[f.Name]
+			if !ok {
+				source = "default"
+			}
+			log.Printf("\tdebug: -%s=%s (%s)", f.Name, f.Value, source)
+		})
+	}
+
+	return nil
+}
+
+// isExcluded reports whether the file is excluded by the config file.
+// Files outside of the config file directory are never excluded.
+func (l *linter) isExcluded(filename string) (bool, error) {
+	if len(l.excludes) == 0 {
+		return false, nil
+	}
+	rel, err := filepath.Rel(l.configDir, filename)
+	if err != nil {
+		return false, err
+	}
+	if strings.HasPrefix(rel, "..") {
+		return false, nil
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range l.excludes {
+		if matchFilePattern(pattern, rel) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,215 @@
+package check
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lintpack-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sources := map[string]string{
+		".linter.yml": `
+enable: [a, "#diagnostic"]
+checkTests: false
+minSeverity: warning
+params:
+  b:
+    limit: 10
+    names: [x, z]
+exclude: ["*_gen.go"]
+`,
+		".linter.json": `{
+  "enable": ["a", "#diagnostic"],
+  "checkTests": false,
+  "minSeverity": "warning",
+  "params": {"b": {"limit": 10, "names": ["x", "z"]}},
+  "exclude": ["*_gen.go"]
+}`,
+	}
+	want := []configFlag{
+		{"enable", "a,#diagnostic"},
+		{"checkTests", "false"},
+		{"minSeverity", "warning"},
+		{"@b.limit", "10"},
+		{"@b.names", "x,z"},
+	}
+	paramKey := (&linter{}).checkerParamKey
+
+	for name, src := range sources {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loadConfig(filename)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if have := cfg.flags(paramKey); !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: flags mismatch:\nhave: %v\nwant: %v", name, have, want)
+		}
+		if !reflect.DeepEqual(cfg.Exclude, []string{"*_gen.go"}) {
+			t.Errorf("%s: exclude mismatch: %v", name, cfg.Exclude)
+		}
+	}
+
+	badFilename := filepath.Join(dir, ".bad.yml")
+	if err := ioutil.WriteFile(badFilename, []byte("enabel: [a]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(badFilename); err == nil {
+		t.Errorf("expected unknown setting error")
+	}
+
+	subdir := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := findConfigFile("linter", subdir), filepath.Join(dir, ".linter.yml"); have != want {
+		t.Errorf("find config: have %q, want %q", have, want)
+	}
+	if have := findConfigFile("nonexisting", subdir); have != "" {
+		t.Errorf("find config: have %q, want empty string", have)
+	}
+}
+
+func TestMatchFilePattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		filename string
+		want     bool
+	}{
+		{"*_gen.go", "a_gen.go", true},
+		{"*_gen.go", "pkg/a_gen.go", true},
+		{"*_gen.go", "a.go", false},
+		{"pkg/*.go", "pkg/a.go", true},
+		{"pkg/*.go", "pkg/sub/a.go", false},
+		{"vendor/**", "vendor/a/b.go", true},
+		{"vendor/**", "vendor.go", false},
+		{"internal/*/legacy/**", "internal/x/legacy/a/b.go", true},
+		{"internal/*/legacy/**", "internal/x/a.go", false},
+		{"./**", "a.go", true},
+		{"./**", "pkg/a.go", true},
+		{"**/**", "a.go", true},
+		{"*/**", "a.go", false},
+	}
+
+	for _, test := range tests {
+		have := matchFilePattern(test.pattern, test.filename)
+		if have != test.want {
+			t.Errorf("match(%q, %q): have %v, want %v",
+				test.pattern, test.filename, have, test.want)
+		}
+	}
+}
+
+func TestMatchDirPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		dir     string
+		want    bool
+	}{
+		{"legacy", "legacy", true},
+		{"legacy/**", "legacy/sub", true},
+		{"legacy", "service", false},
+		{"legacy", ".", false},
+		{"*", ".", false},
+		{".", ".", true},
+		{".", "service", true},
+		{"./**", ".", true},
+		{"**", ".", true},
+		{"**", "service/sub", true},
+	}
+
+	for _, test := range tests {
+		have := matchDirPattern(test.pattern, test.dir)
+		if have != test.want {
+			t.Errorf("match(%q, %q): have %v, want %v",
+				test.pattern, test.dir, have, test.want)
+		}
+	}
+}
+
+func TestConfigValueString(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`1000000`, "1000000"},
+		{`0.5`, "0.5"},
+		{`1e21`, "1000000000000000000000"},
+		{`-3`, "-3"},
+		{`"1e6"`, "1e6"},
+		{`true`, "true"},
+		{`[1000000, "x", 2.5]`, "1000000,x,2.5"},
+	}
+
+	for _, test := range tests {
+		var v interface{}
+		if err := json.Unmarshal([]byte(test.src), &v); err != nil {
+			t.Fatal(err)
+		}
+		if have := configValueString(v); have != test.want {
+			t.Errorf("%s: have %q, want %q", test.src, have, test.want)
+		}
+	}
+}
+
+func TestRelativeConfigExcludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lintpack-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := "exclude: [\"*_gen.go\", \"vendor/**\"]\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "lint.yml"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var l linter
+	if err := l.applyConfig("lint.yml"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filename string
+		want     bool
+	}{
+		{filepath.Join(dir, "a_gen.go"), true},
+		{filepath.Join(dir, "vendor", "x", "a.go"), true},
+		{filepath.Join(dir, "a.go"), false},
+		{filepath.Join(filepath.Dir(dir), "a_gen.go"), false},
+	}
+	for _, test := range tests {
+		have, err := l.isExcluded(test.filename)
+		if err != nil {
+			t.Errorf("%s: %v", test.filename, err)
+			continue
+		}
+		if have != test.want {
+			t.Errorf("%s: have %v, want %v", test.filename, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package check
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// internalErrorExitCode is used when at least one checker crashed.
+// It takes precedence over exit codes selected by -exitCode.
+const internalErrorExitCode = 3
+
+// checkerCrash describes a recovered checker panic.
+type checkerCrash struct {
+	// checker is a name of the crashed checker.
+	checker string
+
+	// location is a file or package being checked during the crash.
+	location string
+
+	// filename is a file the crash is attributed to by the reporters
+	// that describe issues per file. It's the first package file
+	// for the package crashes.
+	filename string
+
+	// value is a recovered panic value.
+	value interface{}
+
+	// stack is a stack trace of the panicking goroutine.
+	stack []byte
+}
+
+// handleCrashes reports checker crashes and disables crashed checkers
+// for the rest of the current package.
+// crashes are indexed in the same way as l.checkers.
+func (l *linter) handleCrashes(crashes []*checkerCrash) {
+	for i, crash := range crashes {
+		if crash == nil {
+			continue
+		}
+		l.crashes = append(l.crashes, crash)
+		l.disabled[l.checkers[i]] = true
+		log.Printf("%s: internal checker error: %s: %v\n%s",
+			crash.location, crash.checker, crash.value, crash.stack)
+		if l.verbose {
+			log.Printf("\tdebug: %s is disabled for the rest of the package", crash.checker)
+		}
+	}
+}
+
+// exitOnCrashes prints a crashes summary and exits
+// if any checker crashed during the run.
+func (l *linter) exitOnCrashes() {
+	if len(l.crashes) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, crash := range l.crashes {
+		counts[crash.checker]++
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s (%d)", name, counts[name])
+	}
+
+	log.Printf("%d internal checker errors: %s",
+		len(l.crashes), strings.Join(parts, ", "))
+	os.Exit(internalErrorExitCode)
+}
+
+// isDisabled reports whether c is disabled for the current package.
+func (l *linter) isDisabled(c *lintpack.Checker) bool {
+	return l.disabled[c]
+}
@@ -0,0 +1,65 @@
+package check
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+type crashWalker struct {
+	ctx   *lintpack.CheckerContext
+	crash bool
+}
+
+func (w *crashWalker) WalkFile(f *ast.File) {
+	if w.crash {
+		var m map[string]int
+		m["x"]++
+	}
+	w.ctx.Warn(f, "ok")
+}
+
+func TestExecCheckersCrash(t *testing.T) {
+	coll := &lintpack.CheckerCollection{
+		URL:      "example.com",
+		Registry: lintpack.NewRegistry(),
+	}
+	for _, name := range []string{"good", "bad"} {
+		crash := name == "bad"
+		info := &lintpack.CheckerInfo{Name: name, Summary: "Example"}
+		coll.AddChecker(info, func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+			return &crashWalker{ctx: ctx, crash: crash}
+		})
+	}
+
+	l := &linter{
+		ctx:      lintpack.NewContext(token.NewFileSet(), nil),
+		disabled: make(map[*lintpack.Checker]bool),
+	}
+	for _, info := range coll.Registry.GetCheckersInfo() {
+		l.checkers = append(l.checkers, coll.Registry.NewChecker(l.ctx, info))
+	}
+
+	f := &ast.File{Name: ast.NewIdent("example")}
+	check := func(c *lintpack.Checker) []lintpack.Warning { return c.Check(f) }
+	for i := 0; i < 2; i++ {
+		warnings := l.execCheckers("example.go", "example.go", check)
+		// Checkers are sorted by name: "bad", "good".
+		if len(warnings[0]) != 0 {
+			t.Errorf("run %d: unexpected warnings from crashed checker", i)
+		}
+		if len(warnings[1]) != 1 {
+			t.Errorf("run %d: have %d warnings, want 1", i, len(warnings[1]))
+		}
+	}
+
+	if len(l.crashes) != 1 {
+		t.Fatalf("have %d crashes, want 1", len(l.crashes))
+	}
+	crash := l.crashes[0]
+	if crash.checker != "bad" || crash.location != "example.go" || crash.filename != "example.go" || len(crash.stack) == 0 {
+		t.Errorf("unexpected crash info: %+v", crash)
+	}
+}
@@ -0,0 +1,191 @@
+package check
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is a number of unchanged lines printed around every hunk.
+const diffContextLines = 3
+
+// diffOpKind describes a single line-level diff operation.
+type diffOpKind byte
+
+const (
+	diffEqual  diffOpKind = ' '
+	diffDelete diffOpKind = '-'
+	diffInsert diffOpKind = '+'
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+// unifiedDiff returns a unified diff that transforms a into b.
+// Returns an empty string if there is no difference.
+func unifiedDiff(filename string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n", filename)
+	fmt.Fprintf(&buf, "+++ %s\n", filename)
+
+	// aLine and bLine are 1-based line numbers of the current op.
+	aLine, bLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == diffEqual {
+			i++
+			aLine++
+			bLine++
+			continue
+		}
+
+		// Found a change; extend it with a leading context
+		// and all following changes that are close enough.
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		aStart := aLine - (i - start)
+		bStart := bLine - (i - start)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != diffEqual {
+				end = j + 1
+				continue
+			}
+			if j-end >= 2*diffContextLines {
+				break
+			}
+		}
+		end += diffContextLines
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		aCount, bCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != diffInsert {
+				aCount++
+			}
+			if op.kind != diffDelete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n",
+			hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, op := range ops[start:end] {
+			buf.WriteByte(byte(op.kind))
+			buf.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		for _, op := range ops[i:end] {
+			if op.kind != diffInsert {
+				aLine++
+			}
+			if op.kind != diffDelete {
+				bLine++
+			}
+		}
+		i = end
+	}
+
+	return buf.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// Empty ranges refer to the line that precedes them.
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a line-level edit script using the longest common
+// subsequence of a and b. Common prefix and suffix are excluded from
+// the LCS computation since fixes usually affect only a few lines.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: diffEqual, line: line})
+	}
+	ops = append(ops, diffLCS(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: diffEqual, line: line})
+	}
+	return ops
+}
+
+func diffLCS(a, b []string) []diffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: diffEqual, line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: diffDelete, line: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: diffInsert, line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{kind: diffDelete, line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{kind: diffInsert, line: b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,201 @@
+package check
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// suggestedFix is a lintpack.Warning fix that is bound to a file.
+type suggestedFix struct {
+	// checker is a name of the checker that suggested the fix.
+	checker string
+
+	// edits are sorted by their start offset.
+	edits []textEdit
+}
+
+// textEdit is a lintpack.TextEdit that uses file offsets instead of token.Pos.
+type textEdit struct {
+	start   int
+	end     int
+	newText string
+}
+
+func (e textEdit) overlaps(other textEdit) bool {
+	if e.start == e.end && other.start == other.end {
+		// Two insertions conflict only if they insert at the same point.
+		return e.start == other.start
+	}
+	return e.start < other.end && other.start < e.end ||
+		e.start == other.start
+}
+
+// addFix records a checker-suggested fix to be applied later.
+func (l *linter) addFix(checker string, fix []lintpack.TextEdit) {
+	if len(fix) == 0 {
+		return
+	}
+
+	filename := l.fset.Position(fix[0].Pos).Filename
+	edits := make([]textEdit, 0, len(fix))
+	for _, e := range fix {
+		if !e.Pos.IsValid() || !e.End.IsValid() {
+			log.Printf("%s: skipping fix with invalid position", checker)
+			return
+		}
+		start := l.fset.Position(e.Pos)
+		end := l.fset.Position(e.End)
+		if start.Filename != filename || end.Filename != filename {
+			log.Printf("%s: skipping fix that spans several files", checker)
+			return
+		}
+		edits = append(edits, textEdit{
+			start:   start.Offset,
+			end:     end.Offset,
+			newText: e.NewText,
+		})
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	if l.fixes == nil {
+		l.fixes = make(map[string][]suggestedFix)
+	}
+	l.fixes[filename] = append(l.fixes[filename], suggestedFix{
+		checker: checker,
+		edits:   edits,
+	})
+}
+
+// applyFixes rewrites files using collected fixes or prints
+// the resulting changes as a unified diff, depending on the options.
+func (l *linter) applyFixes() error {
+	if !l.fix && !l.printDiff {
+		return nil
+	}
+
+	filenames := make([]string, 0, len(l.fixes))
+	for filename := range l.fixes {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		src, err := l.readFile(filename)
+		if err != nil {
+			return err
+		}
+		edits := l.resolveFixes(filename, l.fixes[filename])
+		fixed, err := applyEdits(src, edits)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+
+		if l.printDiff {
+			fmt.Fprint(l.out, unifiedDiff(l.shortenFilename(filename), src, fixed))
+		}
+		if l.fix {
+			if err := writeFile(filename, fixed); err != nil {
+				return err
+			}
+			if l.verbose {
+				log.Printf("\tdebug: fixed %s", filename)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveFixes selects a set of non-conflicting edits from the fixes.
+//
+// Fixes are considered in order of their appearance in the file.
+// A fix is either applied completely or skipped, if any of its
+// edits overlaps with an already accepted edit.
+// Duplicated edits, that can be suggested by several checkers,
+// are applied only once.
+func (l *linter) resolveFixes(filename string, fixes []suggestedFix) []textEdit {
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[i].edits[0].start < fixes[j].edits[0].start
+	})
+
+	var accepted []textEdit
+	acceptedBy := make(map[textEdit]string)
+	for _, fix := range fixes {
+		var newEdits []textEdit
+		conflict := ""
+		for _, e := range fix.edits {
+			if _, ok := acceptedBy[e]; ok {
+				continue // Already applied
+			}
+			for _, other := range accepted {
+				if e.overlaps(other) {
+					conflict = acceptedBy[other]
+					break
+				}
+			}
+			for _, other := range newEdits {
+				if e.overlaps(other) {
+					conflict = fix.checker // Malformed fix
+					break
+				}
+			}
+			if conflict != "" {
+				break
+			}
+			newEdits = append(newEdits, e)
+		}
+		if conflict != "" {
+			log.Printf("%s: skipping %s fix: conflicts with %s fix",
+				l.shortenFilename(filename), fix.checker, conflict)
+			continue
+		}
+		for _, e := range newEdits {
+			accepted = append(accepted, e)
+			acceptedBy[e] = fix.checker
+		}
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].start < accepted[j].start
+	})
+	return accepted
+}
+
+// applyEdits returns a copy of src with all edits applied.
+// Edits must be sorted and must not overlap.
+func applyEdits(src []byte, edits []textEdit) ([]byte, error) {
+	out := make([]byte, 0, len(src))
+	offset := 0
+	for _, e := range edits {
+		if e.start < offset || e.end < e.start || e.end > len(src) {
+			return nil, fmt.Errorf("invalid edit range [%d, %d)", e.start, e.end)
+		}
+		out = append(out, src[offset:e.start]...)
+		out = append(out, e.newText...)
+		offset = e.end
+	}
+	out = append(out, src[offset:]...)
+	return out, nil
+}
+
+func writeFile(filename string, data []byte) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, info.Mode())
+}
+
+func (l *linter) shortenFilename(filename string) string {
+	if !l.shorterErrLocation {
+		return filename
+	}
+	return l.shortenLocation(filename)
+}
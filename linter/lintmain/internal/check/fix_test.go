@@ -0,0 +1,200 @@
+package check
+
+import (
+	"bytes"
+	"go/ast"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+func TestResolveFixes(t *testing.T) {
+	src := "a := foo(x)\nb := bar(y)\n"
+
+	// replace returns an edit that replaces old substring of src.
+	replace := func(old, newText string) textEdit {
+		for i := 0; i+len(old) <= len(src); i++ {
+			if src[i:i+len(old)] == old {
+				return textEdit{start: i, end: i + len(old), newText: newText}
+			}
+		}
+		t.Fatalf("%q not found", old)
+		return textEdit{}
+	}
+
+	tests := []struct {
+		fixes []suggestedFix
+		want  string
+	}{
+		{
+			fixes: []suggestedFix{
+				{checker: "c1", edits: []textEdit{replace("foo", "baz")}},
+				{checker: "c2", edits: []textEdit{replace("bar", "qux")}},
+			},
+			want: "a := baz(x)\nb := qux(y)\n",
+		},
+		{
+			// Identical edits are applied only once.
+			fixes: []suggestedFix{
+				{checker: "c1", edits: []textEdit{replace("foo", "baz")}},
+				{checker: "c2", edits: []textEdit{replace("foo", "baz")}},
+			},
+			want: "a := baz(x)\nb := bar(y)\n",
+		},
+		{
+			// The second fix overlaps with the first one and is skipped
+			// completely, including its non-conflicting edit.
+			fixes: []suggestedFix{
+				{checker: "c1", edits: []textEdit{replace("foo(x)", "x")}},
+				{checker: "c2", edits: []textEdit{
+					replace("foo", "baz"),
+					replace("bar", "qux"),
+				}},
+			},
+			want: "a := x\nb := bar(y)\n",
+		},
+		{
+			// Insertions at the same point conflict.
+			fixes: []suggestedFix{
+				{checker: "c1", edits: []textEdit{{start: 0, end: 0, newText: "// 1\n"}}},
+				{checker: "c2", edits: []textEdit{{start: 0, end: 0, newText: "// 2\n"}}},
+			},
+			want: "// 1\n" + src,
+		},
+	}
+
+	l := &linter{}
+	for i, test := range tests {
+		edits := l.resolveFixes("test.go", test.fixes)
+		have, err := applyEdits([]byte(src), edits)
+		if err != nil {
+			t.Errorf("test %d: apply edits: %v", i, err)
+			continue
+		}
+		if string(have) != test.want {
+			t.Errorf("test %d:\nhave: %q\nwant: %q", i, have, test.want)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n"
+	want := `--- f.go
++++ f.go
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`
+	if have := unifiedDiff("f.go", []byte(a), []byte(b)); have != want {
+		t.Errorf("diff mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+	if have := unifiedDiff("f.go", []byte(a), []byte(a)); have != "" {
+		t.Errorf("expected empty diff for identical inputs, have:\n%s", have)
+	}
+}
+
+// fixWalker reports calls of the named function with the fix
+// returned by newFix.
+type fixWalker struct {
+	ctx    *lintpack.CheckerContext
+	name   string
+	newFix func(call *ast.CallExpr) []lintpack.TextEdit
+}
+
+func (w *fixWalker) WalkFile(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if ok && call.Fun.(*ast.Ident).Name == w.name {
+			w.ctx.WarnFixable(call, w.newFix(call), "call of %s", w.name)
+		}
+		return true
+	})
+}
+
+func TestApplyFixes(t *testing.T) {
+	coll := &lintpack.CheckerCollection{URL: "example.com", Registry: lintpack.NewRegistry()}
+	// rename replaces g calls with g2 calls.
+	coll.AddChecker(&lintpack.CheckerInfo{Name: "rename", Summary: "Example"},
+		func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+			return &fixWalker{ctx: ctx, name: "g", newFix: func(call *ast.CallExpr) []lintpack.TextEdit {
+				return []lintpack.TextEdit{{Pos: call.Fun.Pos(), End: call.Fun.End(), NewText: "g2"}}
+			}}
+		})
+	// zero replaces h calls with 0.
+	coll.AddChecker(&lintpack.CheckerInfo{Name: "zero", Summary: "Example"},
+		func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+			return &fixWalker{ctx: ctx, name: "h", newFix: func(call *ast.CallExpr) []lintpack.TextEdit {
+				return []lintpack.TextEdit{{Pos: call.Pos(), End: call.End(), NewText: "0"}}
+			}}
+		})
+
+	src := `package a
+
+func f() {
+	g(1)
+	g(2) //lintpack:ignore rename suppressed
+	_ = h(g(3))
+	g(4)
+}
+`
+	// g(3) fix conflicts with h(g(3)) fix and is skipped,
+	// g(2) warning is suppressed and g(4) is not changed.
+	fixed := `package a
+
+func f() {
+	g2(1)
+	g(2) //lintpack:ignore rename suppressed
+	_ = 0
+	g(4)
+}
+`
+
+	check := func(dir string) (*linter, string) {
+		l, r := newTestLinter(coll)
+		filename := filepath.Join(dir, "a.go")
+		l.changedLines = changedLines{filename: {4: true, 5: true, 6: true}}
+		checkTestPackage(t, l, r, dir, map[string]string{"a.go": src})
+		return l, filename
+	}
+	readFile := func(filename string) string {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// -fix rewrites the file.
+	l, filename := check(t.TempDir())
+	l.fix = true
+	if err := l.applyFixes(); err != nil {
+		t.Fatal(err)
+	}
+	if have := readFile(filename); have != fixed {
+		t.Errorf("-fix result mismatch:\nhave:\n%s\nwant:\n%s", have, fixed)
+	}
+
+	// -diff prints the changes and leaves the file untouched.
+	l, filename = check(t.TempDir())
+	var out bytes.Buffer
+	l.printDiff = true
+	l.out = &out
+	if err := l.applyFixes(); err != nil {
+		t.Fatal(err)
+	}
+	if have := readFile(filename); have != src {
+		t.Errorf("-diff changed the file:\n%s", have)
+	}
+	if have, want := out.String(), unifiedDiff(filename, []byte(src), []byte(fixed)); have != want {
+		t.Errorf("-diff output mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
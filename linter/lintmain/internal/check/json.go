@@ -0,0 +1,145 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// jsonReport is a document printed by the json output format.
+type jsonReport struct {
+	l *linter
+
+	// listed is a set of checkers that are added to Checkers.
+	// Keys include checker names and param values.
+	listed map[string]bool
+
+	Linter   jsonLinter    `json:"linter"`
+	Checkers []jsonChecker `json:"checkers"`
+	Warnings []jsonWarning `json:"warnings"`
+	Crashes  []jsonCrash   `json:"internalErrors,omitempty"`
+}
+
+// jsonLinter describes the linter that produced the report.
+type jsonLinter struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// jsonChecker describes an enabled checker.
+//
+// Checkers are listed once for every set of their param values,
+// so checkers with per-directory param overrides can be listed several times.
+type jsonChecker struct {
+	Name          string            `json:"name"`
+	Tags          []string          `json:"tags"`
+	Severity      string            `json:"severity"`
+	CollectionURL string            `json:"collectionURL"`
+	Params        map[string]string `json:"params,omitempty"`
+}
+
+// jsonWarning describes a single reported warning.
+//
+// Lines and columns are 1-based, columns are byte offsets.
+type jsonWarning struct {
+	File          string   `json:"file"`
+	Line          int      `json:"line"`
+	Column        int      `json:"column"`
+	EndLine       int      `json:"endLine"`
+	EndColumn     int      `json:"endColumn"`
+	Checker       string   `json:"checker"`
+	Tags          []string `json:"tags"`
+	CollectionURL string   `json:"collectionURL"`
+	Severity      string   `json:"severity"`
+	Message       string   `json:"message"`
+}
+
+// jsonCrash describes a recovered checker panic.
+type jsonCrash struct {
+	Checker  string `json:"checker"`
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func newJSONReport(l *linter) reporter {
+	r := &jsonReport{
+		l:        l,
+		listed:   make(map[string]bool),
+		Linter:   jsonLinter{Name: l.name, Version: l.version},
+		Checkers: []jsonChecker{},
+		Warnings: []jsonWarning{},
+	}
+	for _, c := range l.checkers {
+		r.addChecker(c.Info, c.Params())
+	}
+	return r
+}
+
+// addChecker adds the checker with the given param values
+// to the report checkers list.
+// Checkers changed by per-directory overrides are added on their first warning.
+func (r *jsonReport) addChecker(info *lintpack.CheckerInfo, params lintpack.CheckerParams) {
+	checker := jsonChecker{
+		Name:          info.Name,
+		Tags:          nonNilStrings(info.Tags),
+		Severity:      info.Severity.String(),
+		CollectionURL: info.Collection.URL,
+	}
+	if len(params) != 0 {
+		checker.Params = make(map[string]string, len(params))
+		for pname, param := range params {
+			checker.Params[pname] = param.String()
+		}
+	}
+	key := fmt.Sprint(checker.Name, checker.Params) // Maps are printed sorted
+	if r.listed[key] {
+		return
+	}
+	r.listed[key] = true
+	r.Checkers = append(r.Checkers, checker)
+}
+
+func (r *jsonReport) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	r.addChecker(info, r.l.checkerParams(info))
+	pos := r.l.fset.Position(warn.Node.Pos())
+	end := r.l.fset.Position(warn.Node.End())
+	r.Warnings = append(r.Warnings, jsonWarning{
+		File:          pos.Filename,
+		Line:          pos.Line,
+		Column:        pos.Column,
+		EndLine:       end.Line,
+		EndColumn:     end.Column,
+		Checker:       info.Name,
+		Tags:          nonNilStrings(info.Tags),
+		CollectionURL: info.Collection.URL,
+		Severity:      warn.Severity.String(),
+		Message:       warn.Text,
+	})
+}
+
+func (r *jsonReport) Finish() error {
+	sort.SliceStable(r.Checkers, func(i, j int) bool {
+		return r.Checkers[i].Name < r.Checkers[j].Name
+	})
+	for _, crash := range r.l.crashes {
+		r.Crashes = append(r.Crashes, jsonCrash{
+			Checker:  crash.checker,
+			Location: crash.location,
+			Message:  fmt.Sprint(crash.value),
+		})
+	}
+	enc := json.NewEncoder(r.l.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// nonNilStrings returns list or an empty slice if list is nil.
+// Used to print empty lists as [] instead of null.
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,104 @@
+package check
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// junitReport collects warnings for the junit output format.
+//
+// Every file with warnings becomes a test suite and
+// every warning becomes a failed test case.
+type junitReport struct {
+	l *linter
+
+	// suites maps file names to their test suites.
+	suites map[string]*junitTestSuite
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func newJUnitReport(l *linter) reporter {
+	return &junitReport{
+		l:      l,
+		suites: make(map[string]*junitTestSuite),
+	}
+}
+
+func (r *junitReport) suite(name string) *junitTestSuite {
+	suite := r.suites[name]
+	if suite == nil {
+		suite = &junitTestSuite{Name: name}
+		r.suites[name] = suite
+	}
+	return suite
+}
+
+func (r *junitReport) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	pos := r.l.fset.Position(warn.Node.Pos())
+	suite := r.suite(pos.Filename)
+	suite.Tests++
+	suite.Failures++
+	suite.Cases = append(suite.Cases, junitTestCase{
+		Name:      info.Name,
+		ClassName: pos.String(),
+		Failure: &junitFailure{
+			Message: warn.Text,
+			Type:    warn.Severity.String(),
+			Text:    fmt.Sprintf("%s: %s: %s", pos, info.Name, warn.Text),
+		},
+	})
+}
+
+func (r *junitReport) Finish() error {
+	for _, crash := range r.l.crashes {
+		suite := r.suite(crash.filename)
+		suite.Tests++
+		suite.Errors++
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      crash.checker,
+			ClassName: crash.location,
+			Error: &junitFailure{
+				Message: fmt.Sprintf("internal checker error: %v", crash.value),
+				Type:    "panic",
+				Text:    string(crash.stack),
+			},
+		})
+	}
+
+	var doc junitTestSuites
+	for _, suite := range r.suites {
+		doc.Suites = append(doc.Suites, suite)
+	}
+	sort.Slice(doc.Suites, func(i, j int) bool {
+		return doc.Suites[i].Name < doc.Suites[j].Name
+	})
+	return printXML(r.l.out, doc)
+}
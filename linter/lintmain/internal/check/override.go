@@ -0,0 +1,196 @@
+package check
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-lintpack/lintpack"
+	"golang.org/x/tools/go/packages"
+)
+
+// checkerFilter matches checkers by their names and #tags.
+type checkerFilter struct {
+	names map[string]bool
+	tags  map[string]bool
+}
+
+// newCheckerFilter returns a filter for a list of checker names
+// and tags. Tags are prefixed with "#".
+func newCheckerFilter(keys []string) checkerFilter {
+	filter := checkerFilter{
+		names: make(map[string]bool),
+		tags:  make(map[string]bool),
+	}
+	for _, key := range keys {
+		if strings.HasPrefix(key, "#") {
+			filter.tags[key[len("#"):]] = true
+		} else {
+			filter.names[key] = true
+		}
+	}
+	return filter
+}
+
+// matchTag returns the first checker tag matched by the filter.
+// Returns empty string if no tags are matched.
+func (filter checkerFilter) matchTag(info *lintpack.CheckerInfo) string {
+	for _, tag := range info.Tags {
+		if filter.tags[tag] {
+			return tag
+		}
+	}
+	return ""
+}
+
+func (filter checkerFilter) match(info *lintpack.CheckerInfo) bool {
+	return filter.names[info.Name] || filter.matchTag(info) != ""
+}
+
+// checkerOverride is a resolved per-directory config override.
+type checkerOverride struct {
+	paths   []string
+	enable  checkerFilter
+	disable checkerFilter
+
+	// params maps checker names to their overridden param values.
+	params map[string]map[string]interface{}
+}
+
+// initOverrides resolves config overrides.
+// Param values are parsed and validated in advance.
+func (l *linter) initOverrides() error {
+	infoByName := make(map[string]*lintpack.CheckerInfo, len(l.infoList))
+	for _, info := range l.infoList {
+		infoByName[info.Name] = info
+	}
+
+	for i, o := range l.configOverrides {
+		override := &checkerOverride{
+			paths:   o.Paths,
+			enable:  newCheckerFilter(o.Enable),
+			disable: newCheckerFilter(o.Disable),
+			params:  make(map[string]map[string]interface{}),
+		}
+		for checker, params := range o.Params {
+			info := infoByName[checker]
+			if info == nil {
+				return fmt.Errorf("override #%d: unknown %s checker", i, checker)
+			}
+			for pname, value := range params {
+				param := info.Params[pname]
+				if param == nil {
+					return fmt.Errorf("override #%d: unknown %s param", i,
+						l.checkerParamKey(checker, pname))
+				}
+				// Parse value using a param copy, so the
+				// default value remains untouched.
+				parsed := *param
+				if err := parsed.Set(configValueString(value)); err != nil {
+					return fmt.Errorf("override #%d: %s: %v", i,
+						l.checkerParamKey(checker, pname), err)
+				}
+				if override.params[checker] == nil {
+					override.params[checker] = make(map[string]interface{})
+				}
+				override.params[checker][pname] = parsed.Value
+			}
+		}
+		l.overrides = append(l.overrides, override)
+	}
+
+	l.checkerSets = make(map[string][]*lintpack.Checker)
+	return nil
+}
+
+// checkersFor returns a checkers set for the package.
+// Packages that are not affected by overrides get the base checkers set.
+// Sets for the same overrides combination are shared.
+func (l *linter) checkersFor(pkg *packages.Package) ([]*lintpack.Checker, error) {
+	if len(l.overrides) == 0 || len(pkg.GoFiles) == 0 {
+		return l.baseCheckers, nil
+	}
+	dir, err := filepath.Rel(l.configDir, filepath.Dir(pkg.GoFiles[0]))
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(dir, "..") {
+		return l.baseCheckers, nil
+	}
+	dir = filepath.ToSlash(dir)
+
+	var matched []int
+	for i, o := range l.overrides {
+		for _, pattern := range o.paths {
+			if matchDirPattern(pattern, dir) {
+				matched = append(matched, i)
+				break
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return l.baseCheckers, nil
+	}
+
+	key := fmt.Sprint(matched)
+	if checkers, ok := l.checkerSets[key]; ok {
+		return checkers, nil
+	}
+	checkers := l.newOverriddenCheckers(matched)
+	l.checkerSets[key] = checkers
+	if l.verbose {
+		log.Printf("\tdebug: %s: using overrides %v (%d checkers)",
+			pkg.String(), matched, len(checkers))
+	}
+	return checkers, nil
+}
+
+// checkerParams returns param values of the checker described by info
+// in the current package checkers set.
+func (l *linter) checkerParams(info *lintpack.CheckerInfo) lintpack.CheckerParams {
+	for _, c := range l.checkers {
+		if c.Info == info {
+			return c.Params()
+		}
+	}
+	return info.Params
+}
+
+// newOverriddenCheckers creates a checkers set with overrides applied.
+// Checkers with overridden params get their own param values.
+func (l *linter) newOverriddenCheckers(overrides []int) []*lintpack.Checker {
+	enabled := make(map[string]bool)
+	for _, c := range l.baseCheckers {
+		enabled[c.Info.Name] = true
+	}
+	params := make(map[string]map[string]interface{})
+	for _, i := range overrides {
+		o := l.overrides[i]
+		for _, info := range l.infoList {
+			if o.enable.match(info) {
+				enabled[info.Name] = true
+			}
+			if o.disable.match(info) {
+				enabled[info.Name] = false
+			}
+		}
+		for checker, values := range o.params {
+			if params[checker] == nil {
+				params[checker] = make(map[string]interface{})
+			}
+			for pname, value := range values {
+				params[checker][pname] = value
+			}
+		}
+	}
+
+	var checkers []*lintpack.Checker
+	for _, info := range l.infoList {
+		if enabled[info.Name] {
+			c := lintpack.NewCheckerWithParams(l.ctx, info, params[info.Name])
+			checkers = append(checkers, c)
+		}
+	}
+	return checkers
+}
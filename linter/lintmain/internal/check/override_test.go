@@ -0,0 +1,189 @@
+package check
+
+import (
+	"go/ast"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+	"golang.org/x/tools/go/packages"
+)
+
+// paramWalker reports the limit param value.
+type paramWalker struct {
+	ctx *lintpack.CheckerContext
+}
+
+func (w *paramWalker) WalkFile(f *ast.File) {
+	w.ctx.Warn(f, "%d", w.ctx.Params.Int("limit"))
+}
+
+func TestOverrides(t *testing.T) {
+	coll := &lintpack.CheckerCollection{URL: "example.com"}
+	for _, name := range []string{"overrideTestA", "overrideTestB"} {
+		info := &lintpack.CheckerInfo{
+			Name:    name,
+			Tags:    []string{"overrideTest"},
+			Summary: "Example",
+			Params: lintpack.CheckerParams{
+				"limit": {Value: 10, Usage: "example param"},
+			},
+		}
+		coll.AddChecker(info, func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+			return &paramWalker{ctx: ctx}
+		})
+	}
+
+	l := &linter{
+		ctx:       lintpack.NewContext(token.NewFileSet(), nil),
+		configDir: "/project",
+		configOverrides: []configOverride{
+			{
+				Paths: []string{"."},
+				Params: map[string]map[string]interface{}{
+					"overrideTestA": {"limit": 15},
+				},
+			},
+			{
+				Paths:  []string{"legacy/**"},
+				Enable: []string{"overrideTestB"},
+				Params: map[string]map[string]interface{}{
+					"overrideTestA": {"limit": 20},
+				},
+			},
+			{
+				Paths:   []string{"legacy/generated"},
+				Disable: []string{"#overrideTest"},
+			},
+		},
+	}
+	for _, info := range lintpack.GetCheckersInfo() {
+		if info.HasTag("overrideTest") {
+			l.infoList = append(l.infoList, info)
+		}
+	}
+	l.baseCheckers = []*lintpack.Checker{lintpack.NewChecker(l.ctx, l.infoList[0])}
+	if err := l.initOverrides(); err != nil {
+		t.Fatalf("init overrides: %v", err)
+	}
+
+	f := &ast.File{Name: ast.NewIdent("example")}
+	checkersFor := func(dir string) map[string]int {
+		pkg := &packages.Package{GoFiles: []string{dir + "/a.go"}}
+		checkers, err := l.checkersFor(pkg)
+		if err != nil {
+			t.Fatalf("%s: %v", dir, err)
+		}
+		l.checkers = checkers
+		set := make(map[string]int)
+		for _, c := range checkers {
+			limit, _ := strconv.Atoi(c.Check(f)[0].Text)
+			set[c.Info.Name] = limit
+		}
+		return set
+	}
+
+	tests := []struct {
+		dir  string
+		want map[string]int
+	}{
+		{"/project", map[string]int{"overrideTestA": 15}},
+		{"/project/service", map[string]int{"overrideTestA": 15}},
+		{"/elsewhere/legacy", map[string]int{"overrideTestA": 10}},
+		{"/project/legacy", map[string]int{"overrideTestA": 20, "overrideTestB": 10}},
+		{"/project/legacy/sub", map[string]int{"overrideTestA": 20, "overrideTestB": 10}},
+		{"/project/legacy/generated", map[string]int{}},
+	}
+	for _, test := range tests {
+		have := checkersFor(test.dir)
+		if len(have) != len(test.want) {
+			t.Errorf("%s: have %v, want %v", test.dir, have, test.want)
+			continue
+		}
+		for name, limit := range test.want {
+			if have[name] != limit {
+				t.Errorf("%s: have %v, want %v", test.dir, have, test.want)
+			}
+			// Reporters see the same values as the checkers.
+			info := l.checkers[0].Info
+			if p := l.checkerParams(info).Int("limit"); p != have[info.Name] {
+				t.Errorf("%s: %s reported limit is %d", test.dir, info.Name, p)
+			}
+		}
+	}
+
+	if limit := l.infoList[0].Params.Int("limit"); limit != 10 {
+		t.Errorf("info param value is changed: have %d, want 10", limit)
+	}
+	if len(l.checkerSets) != 3 {
+		t.Errorf("have %d cached checker sets, want 3", len(l.checkerSets))
+	}
+
+	l.configOverrides = []configOverride{{
+		Paths:  []string{"x"},
+		Params: map[string]map[string]interface{}{"overrideTestA": {"limit": "many"}},
+	}}
+	l.overrides = nil
+	if err := l.initOverrides(); err == nil {
+		t.Errorf("expected invalid param value error")
+	}
+}
+
+func TestRelativeConfigOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lintpack-override")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := "overrides:\n  - paths: [legacy]\n    disable: [relativeOverrideTest]\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "lint.yml"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	coll := &lintpack.CheckerCollection{URL: "example.com"}
+	info := &lintpack.CheckerInfo{Name: "relativeOverrideTest", Summary: "Example"}
+	coll.AddChecker(info, func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+		return &paramWalker{ctx: ctx}
+	})
+
+	l := &linter{
+		ctx:      lintpack.NewContext(token.NewFileSet(), nil),
+		infoList: []*lintpack.CheckerInfo{info},
+	}
+	if err := l.applyConfig("lint.yml"); err != nil {
+		t.Fatal(err)
+	}
+	l.baseCheckers = []*lintpack.Checker{lintpack.NewChecker(l.ctx, info)}
+	if err := l.initOverrides(); err != nil {
+		t.Fatal(err)
+	}
+
+	for subdir, want := range map[string]int{"service": 1, "legacy": 0} {
+		pkg := &packages.Package{GoFiles: []string{filepath.Join(dir, subdir, "a.go")}}
+		checkers, err := l.checkersFor(pkg)
+		if err != nil {
+			t.Errorf("%s: %v", subdir, err)
+			continue
+		}
+		if len(checkers) != want {
+			t.Errorf("%s: have %d checkers, want %d", subdir, len(checkers), want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package check
+
+import (
+	"go/ast"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// callPackageWalker reports every call expression of the package
+// and records the number of files it sees on every run.
+type callPackageWalker struct {
+	ctx  *lintpack.CheckerContext
+	runs *[]int
+}
+
+func (w *callPackageWalker) WalkPackage(files []*ast.File) {
+	*w.runs = append(*w.runs, len(files))
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				w.ctx.Warn(call, "call of %s", call.Fun.(*ast.Ident).Name)
+			}
+			return true
+		})
+	}
+}
+
+func TestCheckPackageCheckers(t *testing.T) {
+	var runs []int
+	coll := &lintpack.CheckerCollection{URL: "example.com", Registry: lintpack.NewRegistry()}
+	info := &lintpack.CheckerInfo{Name: "pkgChecker", Summary: "Example", Collection: coll}
+	err := coll.Registry.AddPackageChecker(info, func(ctx *lintpack.CheckerContext) lintpack.PackageWalker {
+		return &callPackageWalker{ctx: ctx, runs: &runs}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	l, r := newTestLinter(coll)
+	l.checkTests = false
+	l.configDir = dir
+	l.excludes = []string{"c.go"}
+	l.reportUnusedIgnores = true
+
+	files := map[string]string{
+		"a.go": `package a
+
+func f() {
+	g(1)
+	g(2) //lintpack:ignore pkgChecker suppressed
+	g(3)
+}
+`,
+		"b.go": `package a
+
+func g(int) { h() }
+`,
+		"c.go": `package a
+
+func h() { g(4) }
+`,
+		"a_test.go": `package a
+
+func test() { g(5) }
+`,
+	}
+
+	have := checkTestPackage(t, l, r, dir, files)
+	want := []string{
+		"a.go:4:2-4:6: pkgChecker: call of g",
+		"a.go:6:2-6:6: pkgChecker: call of g",
+		"b.go:3:15-3:18: pkgChecker: call of h",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("warnings mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+	// The checker runs once and sees the skipped files too.
+	if !reflect.DeepEqual(runs, []int{4}) {
+		t.Errorf("runs: have %v, want [4]", runs)
+	}
+
+	// Only warnings on the changed lines are reported.
+	runs = nil
+	r.warnings = nil
+	l.changedLines = changedLines{
+		filepath.Join(dir, "a.go"): {5: true, 6: true},
+		filepath.Join(dir, "c.go"): {3: true},
+	}
+	have = checkTestPackage(t, l, r, dir, files)
+	want = []string{
+		"a.go:6:2-6:6: pkgChecker: call of g",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("changed lines warnings mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+	if !reflect.DeepEqual(runs, []int{4}) {
+		t.Errorf("changed lines runs: have %v, want [4]", runs)
+	}
+}
@@ -0,0 +1,110 @@
+package check
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-lintpack/lintpack"
+	"github.com/logrusorgru/aurora"
+)
+
+// reporter prints linter results in a specific output format.
+//
+// Warnings are passed in the order they're reported.
+// Reporters that need a complete document, like JSON or XML,
+// collect warnings and print them in Finish.
+type reporter interface {
+	// Warn reports a warning produced by the checker described by info.
+	Warn(info *lintpack.CheckerInfo, warn lintpack.Warning)
+
+	// Finish is called once after all packages are checked.
+	// Checker crashes are available as linter.crashes.
+	Finish() error
+}
+
+// reporters maps -format names to the reporter constructors.
+// Constructors are called after enabled checkers are initialized.
+var reporters = map[string]func(*linter) reporter{
+	"text":        newTextReporter,
+	"json":        newJSONReport,
+	"sarif":       newSarifReport,
+	"checkstyle":  newCheckstyleReport,
+	"junit":       newJUnitReport,
+	"codeclimate": newCodeClimateReport,
+
+	// Selected by -format=template:TEXT or -formatFile.
+	"template": newTemplateReporter,
+}
+
+// reporterNames returns a sorted list of supported output formats.
+func reporterNames() []string {
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// textReporter prints warnings as log lines, like `loc: severity: rule: text`.
+// This is the default reporter.
+type textReporter struct {
+	l *linter
+}
+
+func newTextReporter(l *linter) reporter {
+	return &textReporter{l: l}
+}
+
+func (r *textReporter) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	loc := r.l.fset.Position(warn.Node.Pos()).String()
+	if r.l.shorterErrLocation {
+		loc = r.l.shortenLocation(loc)
+	}
+
+	switch {
+	case r.l.coloredOutput:
+		log.Printf("%v: %v: %v: %v\n",
+			aurora.Magenta(aurora.Bold(loc)),
+			colorizeSeverity(warn.Severity),
+			aurora.Red(info.Name),
+			warn.Text)
+
+	default:
+		log.Printf("%s: %s: %s: %s\n", loc, warn.Severity, info.Name, warn.Text)
+	}
+}
+
+func (r *textReporter) Finish() error {
+	return nil // Warnings are printed immediately
+}
+
+func colorizeSeverity(s lintpack.Severity) aurora.Value {
+	switch s {
+	case lintpack.SeverityError:
+		return aurora.Red(s)
+	case lintpack.SeverityWarning:
+		return aurora.Brown(s)
+	case lintpack.SeverityInfo:
+		return aurora.Cyan(s)
+	default:
+		return aurora.Gray(s)
+	}
+}
+
+// relativePath returns filename relative to the working directory.
+// Returns filename as is if it's outside of the working directory.
+func relativePath(filename string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,217 @@
+package check
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+var updateGolden = flag.Bool("update", false, "whether to update golden report files")
+
+// testReport prints a report for testdata/report/a.go in the given format.
+// Working directory paths are replaced with $WORKDIR, so the report
+// doesn't depend on the repository location.
+func testReport(t *testing.T, format string) []byte {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(wd, "testdata", "report", "a.go")
+
+	var out bytes.Buffer
+	l := &linter{
+		name:    "linter",
+		version: "v1.0.0",
+		fset:    token.NewFileSet(),
+		out:     &out,
+	}
+	f, err := parser.ParseFile(l.fset, filename, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := f.Decls[0].(*ast.FuncDecl).Body.List[0].(*ast.ExprStmt).X.(*ast.CallExpr)
+
+	info := &lintpack.CheckerInfo{
+		Name:     "example",
+		Tags:     []string{"style"},
+		Summary:  "Detects examples",
+		Before:   "f()",
+		After:    "g()",
+		Severity: lintpack.SeverityWarning,
+		Params: lintpack.CheckerParams{
+			"limit": {Value: 10, Usage: "example limit"},
+		},
+	}
+	coll := &lintpack.CheckerCollection{
+		URL:      "https://example.com/checkers",
+		Registry: lintpack.NewRegistry(),
+	}
+	coll.AddChecker(info, func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+		return nil
+	})
+	ctx := lintpack.NewContext(l.fset, nil)
+	l.checkers = []*lintpack.Checker{coll.Registry.NewChecker(ctx, info)}
+	l.crashes = []*checkerCrash{
+		{checker: "example", location: filename, filename: filename, value: "file crash", stack: []byte("stack")},
+		{checker: "other", location: "example.com/report", filename: filename, value: "package crash", stack: []byte("stack")},
+	}
+
+	r := reporters[format](l)
+	r.Warn(info, lintpack.Warning{
+		Node:     call,
+		Text:     "example warning",
+		Severity: lintpack.SeverityWarning,
+	})
+	r.Warn(info, lintpack.Warning{
+		Node:     call.Args[1],
+		Text:     `suspicious "1+1" <expr>`,
+		Severity: lintpack.SeverityError,
+	})
+	if err := r.Finish(); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	report := out.Bytes()
+	for _, path := range []string{
+		(&url.URL{Path: filepath.ToSlash(wd)}).EscapedPath(),
+		filepath.ToSlash(wd),
+	} {
+		report = bytes.Replace(report, []byte(path), []byte("$WORKDIR"), -1)
+	}
+	return report
+}
+
+// checkGoldenReport compares the report in the given format
+// with testdata/report/<format>.golden file.
+// With -update flag, the golden file is rewritten instead.
+func checkGoldenReport(t *testing.T, format string) {
+	have := testReport(t, format)
+	golden := filepath.Join("testdata", "report", format+".golden")
+	if *updateGolden {
+		if err := ioutil.WriteFile(golden, have, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("%s report mismatch:\nhave:\n%s\nwant:\n%s", format, have, want)
+	}
+}
+
+func TestJSONReport(t *testing.T) {
+	checkGoldenReport(t, "json")
+}
+
+func TestCheckstyleReport(t *testing.T) {
+	checkGoldenReport(t, "checkstyle")
+}
+
+func TestJUnitReport(t *testing.T) {
+	checkGoldenReport(t, "junit")
+}
+
+func TestCodeClimateReport(t *testing.T) {
+	checkGoldenReport(t, "codeclimate")
+}
+
+func TestCodeClimateFingerprint(t *testing.T) {
+	r1 := newCodeClimateReport(&linter{}).(*codeClimateReport)
+	r2 := newCodeClimateReport(&linter{}).(*codeClimateReport)
+
+	a1 := r1.fingerprint("a.go", "checker", "text")
+	a2 := r1.fingerprint("a.go", "checker", "text")
+	b1 := r1.fingerprint("b.go", "checker", "text")
+	if a1 == a2 {
+		t.Errorf("identical issues have identical fingerprints")
+	}
+	if a1 == b1 {
+		t.Errorf("issues in different files have identical fingerprints")
+	}
+
+	if have := r2.fingerprint("a.go", "checker", "text"); have != a1 {
+		t.Errorf("fingerprint is not stable: have %s, want %s", have, a1)
+	}
+	if have := r2.fingerprint("a.go", "checker", "text"); have != a2 {
+		t.Errorf("second fingerprint is not stable: have %s, want %s", have, a2)
+	}
+}
+
+func TestReporterNames(t *testing.T) {
+	names := reporterNames()
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Errorf("names are not sorted: %v", names)
+		}
+	}
+	if reporters["text"] == nil {
+		t.Errorf("missing default text reporter")
+	}
+}
+
+func TestOutputTemplate(t *testing.T) {
+	warn := templateWarning{
+		File:     "a.go",
+		Line:     10,
+		Column:   2,
+		Checker:  "example",
+		Tags:     []string{"style", "experimental"},
+		Severity: "warning",
+		Text:     "example warning",
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			`{{.File}}:{{.Line}}: {{.Text}} [{{.Checker}}]`,
+			"a.go:10: example warning [example]\n",
+		},
+		{
+			"{{.File}}:{{.Line}}:{{.Column}}: {{upper .Severity}}: {{.Text}} ({{join .Tags \",\"}})\n",
+			"a.go:10:2: WARNING: example warning (style,experimental)\n",
+		},
+	}
+
+	for _, test := range tests {
+		tmpl, err := parseOutputTemplate(test.format)
+		if err != nil {
+			t.Errorf("parse %q: %v", test.format, err)
+			continue
+		}
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, warn); err != nil {
+			t.Errorf("execute %q: %v", test.format, err)
+			continue
+		}
+		if have := buf.String(); have != test.want {
+			t.Errorf("%q:\nhave: %q\nwant: %q", test.format, have, test.want)
+		}
+	}
+
+	if _, err := parseOutputTemplate("{{.File"); err == nil {
+		t.Errorf("expected parse error for unclosed action")
+	}
+
+	fields := []string{
+		"File", "Line", "Column", "EndLine", "EndColumn",
+		"Checker", "Tags", "Severity", "Text", "CollectionURL",
+	}
+	if have := templateFields(); !reflect.DeepEqual(have, fields) {
+		t.Errorf("template fields:\nhave: %v\nwant: %v", have, fields)
+	}
+}
@@ -0,0 +1,324 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// SARIF 2.1.0 log structure.
+// Only the subset that is used by the linter is described.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool        sarifTool                   `json:"tool"`
+		Invocations []sarifInvocation           `json:"invocations"`
+		BaseIDs     map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+		ColumnKind  string                      `json:"columnKind"`
+		Results     []sarifResult               `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name    string      `json:"name"`
+		Version string      `json:"version,omitempty"`
+		Rules   []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		FullDescription      *sarifMessage      `json:"fullDescription,omitempty"`
+		Help                 *sarifMessage      `json:"help,omitempty"`
+		HelpURI              string             `json:"helpUri,omitempty"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+		Properties           sarifProperties    `json:"properties"`
+	}
+
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+
+	sarifProperties struct {
+		Tags []string `json:"tags"`
+	}
+
+	sarifMessage struct {
+		Text     string `json:"text"`
+		Markdown string `json:"markdown,omitempty"`
+	}
+
+	sarifInvocation struct {
+		ExecutionSuccessful bool                `json:"executionSuccessful"`
+		Notifications       []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+	}
+
+	sarifNotification struct {
+		Level      string           `json:"level"`
+		Message    sarifMessage     `json:"message"`
+		Descriptor *sarifDescriptor `json:"associatedRule,omitempty"`
+	}
+
+	sarifDescriptor struct {
+		ID string `json:"id"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+		Region           sarifRegion      `json:"region"`
+	}
+
+	sarifArtifactLoc struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+	}
+)
+
+// sarifSrcRoot is a base URI id for files inside the working directory.
+const sarifSrcRoot = "%SRCROOT%"
+
+// sarifReport collects warnings for the sarif output format.
+type sarifReport struct {
+	l   *linter
+	log sarifLog
+
+	// ruleIndex maps checker names to their rules indexes.
+	ruleIndex map[string]int
+
+	// workDir is an absolute working directory path with trailing slash.
+	// Empty if it can't be determined.
+	workDir string
+
+	lines *lineCache
+}
+
+func newSarifReport(l *linter) reporter {
+	r := &sarifReport{
+		l:         l,
+		ruleIndex: make(map[string]int),
+		lines:     newLineCache(l.readFile),
+	}
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:    l.name,
+				Version: l.version,
+				Rules:   []sarifRule{},
+			},
+		},
+		ColumnKind: "utf16CodeUnits",
+		Results:    []sarifResult{},
+	}
+	if wd, err := os.Getwd(); err == nil {
+		r.workDir = addTrailingSlash(wd)
+		run.BaseIDs = map[string]sarifArtifactLoc{
+			sarifSrcRoot: {URI: fileURI(r.workDir)},
+		}
+	}
+	r.log = sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	for _, c := range l.checkers {
+		r.rule(c.Info)
+	}
+	return r
+}
+
+// rule returns the checker rule index.
+// Rules for checkers enabled by per-directory overrides
+// are added on their first warning.
+func (r *sarifReport) rule(info *lintpack.CheckerInfo) int {
+	if i, ok := r.ruleIndex[info.Name]; ok {
+		return i
+	}
+	driver := &r.log.Runs[0].Tool.Driver
+	i := len(driver.Rules)
+	r.ruleIndex[info.Name] = i
+	driver.Rules = append(driver.Rules, newSarifRule(info))
+	return i
+}
+
+func newSarifRule(info *lintpack.CheckerInfo) sarifRule {
+	rule := sarifRule{
+		ID:               info.Name,
+		ShortDescription: sarifMessage{Text: info.Summary},
+		HelpURI:          info.Collection.URL,
+		DefaultConfiguration: sarifConfiguration{
+			Level: sarifLevel(info.Severity),
+		},
+		Properties: sarifProperties{Tags: nonNilStrings(info.Tags)},
+	}
+	if info.Details != "" {
+		rule.FullDescription = &sarifMessage{Text: info.Details}
+	}
+
+	var text, markdown strings.Builder
+	for _, s := range []string{info.Summary + ".", info.Details, info.Note} {
+		if s != "" {
+			fmt.Fprintf(&text, "%s\n\n", s)
+			fmt.Fprintf(&markdown, "%s\n\n", s)
+		}
+	}
+	if info.Before != "" {
+		fmt.Fprintf(&text, "Non-compliant code:\n%s\n\nCompliant code:\n%s\n",
+			info.Before, info.After)
+		fmt.Fprintf(&markdown, "Non-compliant code:\n```go\n%s\n```\n\nCompliant code:\n```go\n%s\n```\n",
+			info.Before, info.After)
+	}
+	rule.Help = &sarifMessage{
+		Text:     strings.TrimSpace(text.String()),
+		Markdown: strings.TrimSpace(markdown.String()),
+	}
+	return rule
+}
+
+func (r *sarifReport) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	pos := r.l.fset.Position(warn.Node.Pos())
+	end := r.l.fset.Position(warn.Node.End())
+	ruleIndex := r.rule(info)
+	run := &r.log.Runs[0]
+	run.Results = append(run.Results, sarifResult{
+		RuleID:    info.Name,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevel(warn.Severity),
+		Message:   sarifMessage{Text: warn.Text},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: r.artifactLocation(pos.Filename),
+				Region: sarifRegion{
+					StartLine:   pos.Line,
+					StartColumn: r.lines.utf16Column(pos.Filename, pos.Line, pos.Column),
+					EndLine:     end.Line,
+					EndColumn:   r.lines.utf16Column(end.Filename, end.Line, end.Column),
+				},
+			},
+		}},
+	})
+}
+
+func (r *sarifReport) artifactLocation(filename string) sarifArtifactLoc {
+	if r.workDir != "" && strings.HasPrefix(filename, r.workDir) {
+		rel := filepath.ToSlash(strings.TrimPrefix(filename, r.workDir))
+		return sarifArtifactLoc{URI: (&url.URL{Path: rel}).String(), URIBaseID: sarifSrcRoot}
+	}
+	return sarifArtifactLoc{URI: fileURI(filename)}
+}
+
+func (r *sarifReport) Finish() error {
+	invocation := sarifInvocation{ExecutionSuccessful: len(r.l.crashes) == 0}
+	for _, crash := range r.l.crashes {
+		invocation.Notifications = append(invocation.Notifications, sarifNotification{
+			Level: "error",
+			Message: sarifMessage{
+				Text: fmt.Sprintf("%s: internal checker error: %v", crash.location, crash.value),
+			},
+			Descriptor: &sarifDescriptor{ID: crash.checker},
+		})
+	}
+	r.log.Runs[0].Invocations = []sarifInvocation{invocation}
+
+	enc := json.NewEncoder(r.l.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.log)
+}
+
+func sarifLevel(s lintpack.Severity) string {
+	switch s {
+	case lintpack.SeverityError:
+		return "error"
+	case lintpack.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// fileURI converts an absolute file path to a file URI.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows paths, like C:/dir
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// lineCache holds source lines of the files, loaded on demand.
+type lineCache struct {
+	// readFile returns the file contents.
+	readFile func(filename string) ([]byte, error)
+
+	lines map[string][][]byte
+}
+
+func newLineCache(readFile func(filename string) ([]byte, error)) *lineCache {
+	return &lineCache{
+		readFile: readFile,
+		lines:    make(map[string][][]byte),
+	}
+}
+
+// utf16Column converts a 1-based byte column into a 1-based
+// UTF-16 code units column.
+// Returns byteColumn as is if the file can't be read.
+func (cache *lineCache) utf16Column(filename string, line, byteColumn int) int {
+	lines, ok := cache.lines[filename]
+	if !ok {
+		data, err := cache.readFile(filename)
+		if err == nil {
+			lines = bytes.Split(data, []byte("\n"))
+		}
+		cache.lines[filename] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return byteColumn
+	}
+	src := lines[line-1]
+	if byteColumn-1 < len(src) {
+		src = src[:byteColumn-1]
+	}
+	column := 1
+	for len(src) != 0 {
+		r, size := utf8.DecodeRune(src)
+		src = src[size:]
+		column += len(utf16.Encode([]rune{r}))
+	}
+	return column
+}
@@ -0,0 +1,69 @@
+package check
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUTF16Column(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lintpack-sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "a.go")
+	src := "package a\n\nvar s = \"ж😀\" + x\n"
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line       int
+		byteColumn int
+		want       int
+	}{
+		{1, 1, 1},
+		{1, 9, 9},
+		{3, 9, 9},   // Opening quote
+		{3, 12, 11}, // Emoji: "ж" is 2 bytes and 1 code unit
+		{3, 20, 17}, // x: emoji is 4 bytes and 2 code units
+		{10, 5, 5},  // Out of range line
+	}
+
+	cache := newLineCache(ioutil.ReadFile)
+	for _, test := range tests {
+		have := cache.utf16Column(filename, test.line, test.byteColumn)
+		if have != test.want {
+			t.Errorf("%d:%d: have %d, want %d",
+				test.line, test.byteColumn, have, test.want)
+		}
+	}
+
+	if have := cache.utf16Column(filepath.Join(dir, "missing.go"), 1, 7); have != 7 {
+		t.Errorf("missing file: have %d, want 7", have)
+	}
+}
+
+func TestSarifReport(t *testing.T) {
+	checkGoldenReport(t, "sarif")
+}
+
+func TestSarifArtifactLocation(t *testing.T) {
+	r := &sarifReport{workDir: "/work dir/"}
+	tests := []struct {
+		filename string
+		want     sarifArtifactLoc
+	}{
+		{"/work dir/a.go", sarifArtifactLoc{URI: "a.go", URIBaseID: sarifSrcRoot}},
+		{"/work dir/sub dir/a#1%.go", sarifArtifactLoc{URI: "sub%20dir/a%231%25.go", URIBaseID: sarifSrcRoot}},
+		{"/other dir/a.go", sarifArtifactLoc{URI: "file:///other%20dir/a.go"}},
+	}
+	for _, test := range tests {
+		if have := r.artifactLocation(test.filename); have != test.want {
+			t.Errorf("%s: have %+v, want %+v", test.filename, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+
+	"github.com/go-lintpack/lintpack"
+	"golang.org/x/tools/go/packages"
+)
+
+// Session checks packages on demand, like for the language server.
+//
+// Session uses the same checkers plugin, command-line flags, config file
+// and warning filters as the check sub-command, so the same sources
+// get the same warnings. Output settings, fixes application and
+// the results cache are not used.
+type Session struct {
+	l *linter
+}
+
+// Warning is a warning reported by the session checker.
+type Warning struct {
+	Info *lintpack.CheckerInfo
+	lintpack.Warning
+}
+
+// NewSession loads the checkers plugin and parses the command-line flags.
+//
+// linterName and linterVersion describe the linter,
+// the linter name is used to find the config file.
+func NewSession(linterName, linterVersion string) (*Session, error) {
+	sizes := types.SizesFor("gc", runtime.GOARCH)
+	if sizes == nil {
+		return nil, fmt.Errorf("can't find sizes info for %s", runtime.GOARCH)
+	}
+
+	l := &linter{
+		name:     linterName,
+		version:  linterVersion,
+		infoList: lintpack.GetCheckersInfo(),
+		out:      ioutil.Discard,
+		fset:     token.NewFileSet(),
+	}
+	l.ctx = lintpack.NewContext(l.fset, sizes)
+
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"load plugin", l.loadPlugin},
+		{"bind checker params", l.bindCheckerParams},
+		{"bind default enabled list", l.bindDefaultEnabledList},
+		{"parse args", l.parseArgs},
+		{"init checkers", l.initCheckers},
+	}
+	for _, step := range steps {
+		if err := step.fn(); err != nil {
+			return nil, fmt.Errorf("%s: %v", step.name, err)
+		}
+	}
+	l.cache = nil
+	return &Session{l: l}, nil
+}
+
+// Verbose reports whether the debug output is enabled by the -v flag.
+func (s *Session) Verbose() bool { return s.l.verbose }
+
+// CheckFile checks the package that contains the file
+// and returns warnings for that file.
+//
+// overlay maps file names to their contents that are used
+// instead of the files on disk. Returned file set describes
+// the warning positions.
+//
+// Facts of the dependencies are not available, since
+// the dependencies are not checked.
+func (s *Session) CheckFile(filename string, overlay map[string][]byte) (*token.FileSet, []Warning, error) {
+	l := s.l
+	// Checkers share the context, so the new
+	// file set is visible to all of them.
+	l.fset = token.NewFileSet()
+	l.ctx.FileSet = l.fset
+	l.fixes = nil
+	l.crashes = nil
+	l.overlay = overlay
+
+	cfg := packages.Config{
+		Mode:    packages.LoadSyntax,
+		Tests:   true,
+		Fset:    l.fset,
+		Dir:     filepath.Dir(filename),
+		Overlay: overlay,
+	}
+	pkgs, err := loadPackages(&cfg, []string{"."})
+	if err != nil {
+		return nil, nil, err
+	}
+	pkg := l.findPackage(pkgs, filename)
+	if pkg == nil {
+		return nil, nil, errors.New("file is not loaded")
+	}
+
+	r := &sessionReporter{l: l, filename: filename}
+	l.reporter = r
+	if err := l.checkPackage(pkg); err != nil {
+		return nil, nil, err
+	}
+	return l.fset, r.warnings, nil
+}
+
+// findPackage returns a package that has the file syntax.
+// Returns nil if there is no such package.
+func (l *linter) findPackage(pkgs []*packages.Package, filename string) *packages.Package {
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			if l.fset.Position(f.Pos()).Filename == filename {
+				return pkg
+			}
+		}
+	}
+	return nil
+}
+
+// sessionReporter collects warnings of the checked file.
+type sessionReporter struct {
+	l        *linter
+	filename string
+	warnings []Warning
+}
+
+func (r *sessionReporter) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	if r.l.fset.Position(warn.Node.Pos()).Filename == r.filename {
+		r.warnings = append(r.warnings, Warning{Info: info, Warning: warn})
+	}
+}
+
+func (r *sessionReporter) Finish() error { return nil }
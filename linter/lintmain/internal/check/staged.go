@@ -0,0 +1,102 @@
+package check
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// stagedSources describes the git index state of the Go files.
+type stagedSources struct {
+	// files is a set of staged Go files, absolute paths.
+	files map[string]bool
+
+	// overlay maps absolute file paths to their index contents.
+	// It includes staged files and files with unstaged changes,
+	// so the loaded packages match the index.
+	overlay map[string][]byte
+}
+
+// loadStagedSources reads the git index contents of the
+// Go files that differ from HEAD or the working tree.
+//
+// Only files that exist in the working tree are included,
+// since the packages loader doesn't support new overlay files.
+// Untracked files are checked as is.
+func loadStagedSources() (*stagedSources, error) {
+	// The root keeps symlinks, like the loaded file names,
+	// so the overlay applies to symlinked checkouts as well.
+	root, err := gitRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	// Both lists are relative to the repository root.
+	staged, err := runGit("diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+	unstaged, err := runGit("diff", "--name-only", "-z", "--diff-filter=M")
+	if err != nil {
+		return nil, err
+	}
+
+	sources := &stagedSources{
+		files:   make(map[string]bool),
+		overlay: make(map[string][]byte),
+	}
+	add := func(list []byte, isStaged bool) error {
+		for _, name := range bytes.Split(list, []byte{0}) {
+			if !strings.HasSuffix(string(name), ".go") {
+				continue
+			}
+			filename := filepath.Join(root, filepath.FromSlash(string(name)))
+			if _, err := os.Stat(filename); err != nil {
+				continue // Removed from the working tree
+			}
+			if isStaged {
+				sources.files[filename] = true
+			}
+			if _, ok := sources.overlay[filename]; ok {
+				continue
+			}
+			// ":path" is a path relative to the repository root
+			// that refers to the index contents.
+			src, err := runGit("show", ":"+string(name))
+			if err != nil {
+				return err
+			}
+			sources.overlay[filename] = src
+		}
+		return nil
+	}
+	if err := add(staged, true); err != nil {
+		return nil, err
+	}
+	if err := add(unstaged, false); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// isStaged reports whether the warning belongs to a staged file.
+// All warnings are staged unless -staged is set.
+func (l *linter) isStaged(warn lintpack.Warning) bool {
+	if l.staged == nil {
+		return true
+	}
+	return l.staged.files[l.fset.Position(warn.Node.Pos()).Filename]
+}
+
+// readFile returns the file contents that were used to load the program.
+// Under -staged, these are the git index contents.
+func (l *linter) readFile(filename string) ([]byte, error) {
+	if src, ok := l.overlay[filename]; ok {
+		return src, nil
+	}
+	return ioutil.ReadFile(filename)
+}
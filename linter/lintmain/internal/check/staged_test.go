@@ -0,0 +1,173 @@
+package check
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// newGitRepo creates a temporary git repository and makes it
+// the working directory. Returns a cleanup function that
+// restores the working directory and removes the repository.
+func newGitRepo(t *testing.T) (dir string, cleanup func()) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "lintpack-staged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The git root is reported with symlinks resolved.
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	cleanup = func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	}
+	if _, err := runGit("init", "-q"); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return dir, cleanup
+}
+
+func writeTestFile(t *testing.T, filename, src string) {
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustRunGit(t *testing.T, args ...string) {
+	if _, err := runGit(args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadStagedSources(t *testing.T) {
+	dir, cleanup := newGitRepo(t)
+	defer cleanup()
+
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	c := filepath.Join(dir, "c.go")
+	writeTestFile(t, a, "package a // 1\n")
+	writeTestFile(t, b, "package a // 1\n")
+	mustRunGit(t, "add", ".")
+	mustRunGit(t, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "-m", "init")
+
+	// a.go has both staged and unstaged changes.
+	writeTestFile(t, a, "package a // ж\n")
+	mustRunGit(t, "add", "a.go")
+	writeTestFile(t, a, "package a // 333\n")
+	// b.go has unstaged changes only.
+	writeTestFile(t, b, "package a // 2\n")
+	// c.go is a new staged file.
+	writeTestFile(t, c, "package a // 1\n")
+	mustRunGit(t, "add", "c.go")
+	// d.go is untracked.
+	writeTestFile(t, filepath.Join(dir, "d.go"), "package a\n")
+
+	sources, err := loadStagedSources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFiles := map[string]bool{a: true, c: true}
+	if !reflect.DeepEqual(sources.files, wantFiles) {
+		t.Errorf("files:\nhave: %v\nwant: %v", sources.files, wantFiles)
+	}
+	wantOverlay := map[string]string{
+		a: "package a // ж\n",
+		b: "package a // 1\n",
+		c: "package a // 1\n",
+	}
+	haveOverlay := make(map[string]string)
+	for filename, src := range sources.overlay {
+		haveOverlay[filename] = string(src)
+	}
+	if !reflect.DeepEqual(haveOverlay, wantOverlay) {
+		t.Errorf("overlay:\nhave: %v\nwant: %v", haveOverlay, wantOverlay)
+	}
+
+	// Staged files are matched against the loaded file names,
+	// that keep symlinks in the working directory path.
+	link, cleanupLink := chdirSymlink(t, dir)
+	defer cleanupLink()
+	linked, err := loadStagedSources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkA := filepath.Join(link, "a.go")
+	if !linked.files[linkA] || string(linked.overlay[linkA]) != wantOverlay[a] {
+		t.Errorf("symlinked checkout: files %v, overlay %q", linked.files, linked.overlay[linkA])
+	}
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/a\n")
+	fset := token.NewFileSet()
+	cfg := &packages.Config{Mode: packages.LoadSyntax, Fset: fset, Overlay: linked.overlay}
+	pkgs, err := loadPackages(cfg, []string{"."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("unexpected packages: %v", pkgs)
+	}
+	loaded := false
+	for _, f := range pkgs[0].Syntax {
+		if fset.Position(f.Pos()).Filename != linkA {
+			continue
+		}
+		loaded = true
+		// Index contents have "ж" comment.
+		if text := f.Comments[0].Text(); text != "ж\n" {
+			t.Errorf("%s is not loaded from the index: %q comment", linkA, text)
+		}
+	}
+	if !loaded {
+		t.Errorf("%s is not loaded", linkA)
+	}
+
+	// Reports read the index contents as well.
+	l := &linter{staged: sources, overlay: sources.overlay}
+	if src, err := l.readFile(a); err != nil || string(src) != wantOverlay[a] {
+		t.Errorf("readFile(a.go): have %q, %v; want %q", src, err, wantOverlay[a])
+	}
+	r := newSarifReport(l).(*sarifReport)
+	if have := r.lines.utf16Column(a, 1, 16); have != 15 {
+		// "ж" is 2 bytes and 1 UTF-16 code unit.
+		t.Errorf("sarif column: have %d, want 15", have)
+	}
+}
+
+func TestLoadStagedSourcesWithoutHead(t *testing.T) {
+	dir, cleanup := newGitRepo(t)
+	defer cleanup()
+
+	a := filepath.Join(dir, "a.go")
+	writeTestFile(t, a, "package a\n")
+	mustRunGit(t, "add", "a.go")
+
+	sources, err := loadStagedSources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sources.files, map[string]bool{a: true}) {
+		t.Errorf("files: have %v, want only %s", sources.files, a)
+	}
+	if string(sources.overlay[a]) != "package a\n" {
+		t.Errorf("overlay: have %q, want %q", sources.overlay[a], "package a\n")
+	}
+}
@@ -0,0 +1,220 @@
+package check
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// Suppression directives.
+//
+// `//lintpack:ignore names reason` placed at the end of a line
+// suppresses warnings of that line. Placed on its own line, it
+// suppresses warnings of the following statement or declaration,
+// including blocks and whole functions.
+//
+// `//lintpack:file-ignore names reason` suppresses warnings of the file.
+//
+// names is a comma-separated list of checker names and #tags.
+const (
+	ignoreDirective     = "//lintpack:ignore"
+	fileIgnoreDirective = "//lintpack:file-ignore"
+)
+
+// suppressionInfo describes a pseudo-checker that reports
+// problems with the suppression directives.
+var suppressionInfo = &lintpack.CheckerInfo{
+	Name:       "suppression",
+	Summary:    "Reports malformed and unused suppression directives",
+	Severity:   lintpack.SeverityWarning,
+	Collection: &lintpack.CheckerCollection{URL: "https://github.com/go-lintpack/lintpack"},
+}
+
+// suppression is a parsed suppression directive.
+type suppression struct {
+	comment *ast.Comment
+	filter  checkerFilter
+	reason  string
+
+	// fileScope is set for file-ignore directives.
+	// fromLine and toLine are not used for them.
+	fileScope bool
+
+	// [fromLine, toLine] is a range of suppressed lines.
+	fromLine int
+	toLine   int
+
+	// used is set when directive suppresses at least one warning.
+	used bool
+}
+
+func (s *suppression) matches(info *lintpack.CheckerInfo, line int) bool {
+	return s.filter.match(info) &&
+		(s.fileScope || s.fromLine <= line && line <= s.toLine)
+}
+
+// parseSuppressions returns suppression directives of the file.
+// Malformed directives are returned as warnings.
+//
+// If requireReason is true, directives without a reason are malformed.
+//
+// src is the file contents, it's used to find trailing directives.
+func parseSuppressions(fset *token.FileSet, f *ast.File, src []byte, requireReason bool) ([]*suppression, []lintpack.Warning) {
+	var directives []*suppression
+	var problems []lintpack.Warning
+
+	// nodes is a list of statements and declarations that can be
+	// suppressed by the own-line directive, in source order.
+	var nodes []ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case ast.Stmt, ast.Decl, ast.Spec, *ast.Field:
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			var text string
+			fileScope := false
+			switch {
+			case strings.HasPrefix(c.Text, fileIgnoreDirective+" "):
+				text = strings.TrimPrefix(c.Text, fileIgnoreDirective)
+				fileScope = true
+			case strings.HasPrefix(c.Text, ignoreDirective+" "):
+				text = strings.TrimPrefix(c.Text, ignoreDirective)
+			case c.Text == ignoreDirective || c.Text == fileIgnoreDirective:
+			default:
+				continue
+			}
+
+			fields := strings.Fields(text)
+			if len(fields) == 0 {
+				problems = append(problems, lintpack.Warning{
+					Node:     c,
+					Text:     "suppression directive: missing checker names",
+					Severity: suppressionInfo.Severity,
+				})
+				continue
+			}
+			s := &suppression{
+				comment:   c,
+				filter:    newCheckerFilter(strings.Split(fields[0], ",")),
+				reason:    strings.Join(fields[1:], " "),
+				fileScope: fileScope,
+			}
+			if requireReason && s.reason == "" {
+				problems = append(problems, lintpack.Warning{
+					Node:     c,
+					Text:     "suppression directive: missing reason",
+					Severity: suppressionInfo.Severity,
+				})
+				continue
+			}
+			if !fileScope {
+				s.fromLine, s.toLine = suppressedLines(fset, c, src, nodes)
+			}
+			directives = append(directives, s)
+		}
+	}
+
+	return directives, problems
+}
+
+// suppressedLines returns a range of lines suppressed by the comment.
+func suppressedLines(fset *token.FileSet, c *ast.Comment, src []byte, nodes []ast.Node) (int, int) {
+	line := fset.Position(c.Pos()).Line
+	if isTrailingComment(fset, c, src) {
+		return line, line
+	}
+
+	var next ast.Node
+	for _, n := range nodes {
+		if n.Pos() < c.Pos() {
+			continue
+		}
+		// The first node after the comment. Nodes with the same
+		// position are ordered from outer to inner ones.
+		if next == nil {
+			next = n
+		}
+		if n.Pos() != next.Pos() {
+			break
+		}
+	}
+
+	if next == nil {
+		return line + 1, line + 1
+	}
+	return fset.Position(next.Pos()).Line, fset.Position(next.End()).Line
+}
+
+// isTrailingComment reports whether the comment follows some code
+// on its line, like the continuation line of a multi-line call.
+// Comments are considered to be on their own lines if src can't be read.
+func isTrailingComment(fset *token.FileSet, c *ast.Comment, src []byte) bool {
+	tf := fset.File(c.Pos())
+	end := tf.Offset(c.Pos())
+	start := tf.Offset(tf.LineStart(tf.Line(c.Pos())))
+	if end > len(src) {
+		return false
+	}
+	return len(bytes.TrimSpace(src[start:end])) != 0
+}
+
+// collectSuppressions parses the file suppression directives.
+// Malformed directives are reported immediately.
+func (l *linter) collectSuppressions(f *ast.File) {
+	src, _ := l.readFile(l.fset.Position(f.Pos()).Filename) // Trailing directives are not found on error
+	directives, problems := parseSuppressions(l.fset, f, src, l.requireIgnoreReason)
+	l.suppressions[l.fset.File(f.Pos())] = directives
+	for _, warn := range problems {
+		l.report(suppressionInfo, warn)
+	}
+}
+
+// isSuppressed reports whether the warning is suppressed by a directive.
+// All matching directives are marked as used.
+func (l *linter) isSuppressed(info *lintpack.CheckerInfo, warn lintpack.Warning) bool {
+	pos := l.fset.Position(warn.Node.Pos())
+	suppressed := false
+	for _, s := range l.suppressions[l.fset.File(warn.Node.Pos())] {
+		if s.matches(info, pos.Line) {
+			s.used = true
+			suppressed = true
+		}
+	}
+	return suppressed
+}
+
+// reportUnusedSuppressions reports directives that suppressed nothing.
+//
+// Directives that only refer to checkers that are not enabled
+// for the current package are never reported.
+func (l *linter) reportUnusedSuppressions(files []*ast.File) {
+	for _, f := range files {
+		for _, s := range l.suppressions[l.fset.File(f.Pos())] {
+			if s.used || !l.refersEnabledChecker(s) {
+				continue
+			}
+			l.report(suppressionInfo, lintpack.Warning{
+				Node:     s.comment,
+				Text:     "unused suppression directive: " + s.comment.Text,
+				Severity: suppressionInfo.Severity,
+			})
+		}
+	}
+}
+
+func (l *linter) refersEnabledChecker(s *suppression) bool {
+	for _, c := range l.checkers {
+		if !l.isDisabled(c) && s.filter.match(c.Info) {
+			return true
+		}
+	}
+	return false
+}
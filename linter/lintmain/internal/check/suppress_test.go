@@ -0,0 +1,139 @@
+package check
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	src := `package example
+
+//lintpack:file-ignore fileChecker generated-like file
+
+func f() {
+	x := 1 //lintpack:ignore lineChecker trailing
+	_ = x
+
+	//lintpack:ignore blockChecker,#style own line
+	if x > 0 {
+		println(x)
+	}
+	println()
+}
+
+//lintpack:ignore funcChecker whole function
+func g() {
+	println()
+}
+
+//lintpack:ignore
+//lintpack:ignore noReason
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "example.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	directives, problems := parseSuppressions(fset, f, []byte(src), true)
+	if len(problems) != 2 {
+		t.Errorf("have %d problems, want 2", len(problems))
+	}
+
+	checker := func(name string, tags ...string) *lintpack.CheckerInfo {
+		return &lintpack.CheckerInfo{Name: name, Tags: tags}
+	}
+	suppressed := func(info *lintpack.CheckerInfo, line int) bool {
+		for _, s := range directives {
+			if s.matches(info, line) {
+				return true
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		info *lintpack.CheckerInfo
+		line int
+		want bool
+	}{
+		{checker("fileChecker"), 1, true},
+		{checker("fileChecker"), 18, true},
+
+		{checker("lineChecker"), 6, true},
+		{checker("lineChecker"), 7, false},
+
+		{checker("blockChecker"), 9, false},
+		{checker("blockChecker"), 10, true},
+		{checker("blockChecker"), 12, true},
+		{checker("blockChecker"), 13, false},
+		{checker("other", "style"), 11, true},
+		{checker("other"), 11, false},
+
+		{checker("funcChecker"), 17, true},
+		{checker("funcChecker"), 18, true},
+		{checker("funcChecker"), 19, true},
+		{checker("funcChecker"), 13, false},
+
+		{checker("noReason"), 23, false},
+	}
+	for _, test := range tests {
+		have := suppressed(test.info, test.line)
+		if have != test.want {
+			t.Errorf("%s at line %d: have %v, want %v",
+				test.info.Name, test.line, have, test.want)
+		}
+	}
+
+	directives, problems = parseSuppressions(fset, f, []byte(src), false)
+	if len(problems) != 1 {
+		t.Errorf("have %d problems without required reason, want 1", len(problems))
+	}
+	if len(directives) != 5 {
+		t.Errorf("have %d directives without required reason, want 5", len(directives))
+	}
+}
+
+func TestCheckSuppressions(t *testing.T) {
+	coll := &lintpack.CheckerCollection{URL: "example.com", Registry: lintpack.NewRegistry()}
+	for _, name := range []string{"callChecker", "otherChecker"} {
+		coll.AddChecker(&lintpack.CheckerInfo{Name: name, Summary: "Example"},
+			func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+				return &callWalker{ctx: ctx}
+			})
+	}
+	l, r := newTestLinter(coll)
+	// Only callChecker is enabled.
+	l.baseCheckers = l.baseCheckers[:1]
+	l.reportUnusedIgnores = true
+
+	src := `package a
+
+func f() {
+	g(1,
+		h(nil)) //lintpack:ignore callChecker continuation line
+	g(2)
+	g(3) //lintpack:ignore callChecker trailing
+	//lintpack:ignore callChecker own line
+	g(4,
+		h(5))
+	g(6) //lintpack:ignore otherChecker disabled checker
+	_ = 7 //lintpack:ignore callChecker unused
+}
+`
+	have := checkTestPackage(t, l, r, t.TempDir(), map[string]string{"a.go": src})
+	want := []string{
+		"a.go:11:2-11:6: callChecker: call of g",
+		"a.go:12:8-12:44: suppression: unused suppression directive: //lintpack:ignore callChecker unused",
+		"a.go:4:2-5:10: callChecker: call of g",
+		"a.go:6:2-6:6: callChecker: call of g",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("warnings mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+}
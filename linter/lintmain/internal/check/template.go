@@ -0,0 +1,103 @@
+package check
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// templateWarning is a warning representation passed to
+// the user-defined output templates.
+type templateWarning struct {
+	// File is a warning file path, relative to the working directory
+	// if the file is inside it.
+	File string
+
+	// Line and Column are 1-based warning start position.
+	// Column is measured in bytes.
+	Line   int
+	Column int
+
+	// EndLine and EndColumn are 1-based warning end position.
+	EndLine   int
+	EndColumn int
+
+	// Checker is a name of the checker that produced the warning.
+	Checker string
+
+	// Tags is a list of the checker tags.
+	Tags []string
+
+	// Severity is a warning severity, like "warning" or "error".
+	Severity string
+
+	// Text is a warning message.
+	Text string
+
+	// CollectionURL is the checker collection URL.
+	CollectionURL string
+}
+
+// templateFields returns the names of templateWarning fields
+// that can be used inside the output templates.
+func templateFields() []string {
+	typ := reflect.TypeOf(templateWarning{})
+	fields := make([]string, typ.NumField())
+	for i := range fields {
+		fields[i] = typ.Field(i).Name
+	}
+	return fields
+}
+
+// templateFuncs are functions available inside the output templates.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseOutputTemplate parses a user-defined output template.
+// Every executed template is followed by a newline,
+// unless text already ends with one.
+func parseOutputTemplate(text string) (*template.Template, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New("format").Funcs(templateFuncs).Parse(text)
+}
+
+// templateReporter prints every warning using a user-defined template.
+type templateReporter struct {
+	l *linter
+}
+
+func newTemplateReporter(l *linter) reporter {
+	return &templateReporter{l: l}
+}
+
+func (r *templateReporter) Warn(info *lintpack.CheckerInfo, warn lintpack.Warning) {
+	pos := r.l.fset.Position(warn.Node.Pos())
+	end := r.l.fset.Position(warn.Node.End())
+	data := templateWarning{
+		File:          relativePath(pos.Filename),
+		Line:          pos.Line,
+		Column:        pos.Column,
+		EndLine:       end.Line,
+		EndColumn:     end.Column,
+		Checker:       info.Name,
+		Tags:          nonNilStrings(info.Tags),
+		Severity:      warn.Severity.String(),
+		Text:          warn.Text,
+		CollectionURL: info.Collection.URL,
+	}
+	if err := r.l.formatTemplate.Execute(r.l.out, data); err != nil {
+		log.Fatalf("executing output template: %v", err)
+	}
+}
+
+func (r *templateReporter) Finish() error {
+	return nil // Warnings are printed immediately
+}
@@ -0,0 +1,5 @@
+package report
+
+func f() {
+	println("ж😀", 1+1)
+}
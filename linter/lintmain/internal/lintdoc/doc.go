@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"text/template"
 
 	"github.com/go-lintpack/lintpack"
@@ -39,6 +40,7 @@ func printDoc(name string) {
 	tmplString := `{{.Checker.Name}} checker documentation
 URL: {{.Checker.Collection.URL}}
 Tags: {{.Checker.Tags}}
+Severity: {{.Checker.Severity}}
 
 {{.Checker.Summary}}.
 {{ if .Checker.Details }}
@@ -58,27 +60,47 @@ Compliant code:
 Checker parameters:
 {{- range $key, $_ := .Checker.Params }}
   -@{{$.Checker.Name}}.{{$key}} {{index $.ParamTypes $key}}
-    	{{.Usage}} (default {{.Value}})
+    	{{.Usage}} (default {{.String}})
+{{- with .Enum }}
+    	allowed values: {{join . ", "}}
+{{- end }}
+{{- with index $.ParamRanges $key }}
+    	range: {{.}}
+{{- end }}
 {{- end }}
 {{- end }}
 `
 
 	var templateData struct {
-		Checker    *lintpack.CheckerInfo
-		ParamTypes map[string]string
+		Checker     *lintpack.CheckerInfo
+		ParamTypes  map[string]string
+		ParamRanges map[string]string
 	}
 	templateData.Checker = info
 	templateData.ParamTypes = make(map[string]string)
+	templateData.ParamRanges = make(map[string]string)
 	for pname, p := range info.Params {
 		templateData.ParamTypes[pname] = fmt.Sprintf("%T", p.Value)
+		if p.Min != nil || p.Max != nil {
+			templateData.ParamRanges[pname] = fmt.Sprintf("[%s, %s]",
+				formatBound(p.Min, "-inf"), formatBound(p.Max, "+inf"))
+		}
 	}
 
-	tmpl := template.Must(template.New("doc").Parse(tmplString))
+	funcs := template.FuncMap{"join": strings.Join}
+	tmpl := template.Must(template.New("doc").Funcs(funcs).Parse(tmplString))
 	if err := tmpl.Execute(os.Stdout, templateData); err != nil {
 		panic(fmt.Sprintf("executing checker doc template: %v", err))
 	}
 }
 
+func formatBound(bound interface{}, unbound string) string {
+	if bound == nil {
+		return unbound
+	}
+	return fmt.Sprint(bound)
+}
+
 func findInfoByName(name string) *lintpack.CheckerInfo {
 	for _, info := range lintpack.GetCheckersInfo() {
 		if info.Name == name {
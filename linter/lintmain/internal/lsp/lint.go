@@ -0,0 +1,165 @@
+package lsp
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-lintpack/lintpack"
+)
+
+// lint checks the package of the document and publishes the document diagnostics.
+//
+// Packages that can't be loaded, like ones with syntax errors
+// during the editing, are reported only in the debug output.
+// Fixes of the previously published diagnostics are dropped,
+// since they may no longer match the document.
+func (s *server) lint(uri string) error {
+	if !isGoFile(uri) {
+		return nil
+	}
+	filename, err := uriFilename(uri)
+	if err != nil {
+		return err
+	}
+
+	diagnostics, actions, err := s.check(filename)
+	if err != nil {
+		delete(s.fixes, uri)
+		if s.verbose {
+			log.Printf("\tdebug: %s: %v", filename, err)
+		}
+		return nil
+	}
+	s.fixes[uri] = actions
+
+	params := publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics}
+	if doc := s.docs[filename]; doc != nil {
+		version := doc.version
+		params.Version = &version
+	}
+	return s.notify("textDocument/publishDiagnostics", params)
+}
+
+// check runs enabled checkers over the package that contains
+// the file and returns diagnostics for the file warnings.
+// Fixes suggested by the checkers are returned as code actions.
+//
+// Warnings are filtered in the same way as in the check sub-command.
+// Open documents contents are used instead of the files on disk.
+func (s *server) check(filename string) ([]diagnostic, []codeAction, error) {
+	overlay := make(map[string][]byte, len(s.docs))
+	for name, doc := range s.docs {
+		overlay[name] = doc.text
+	}
+	fset, warnings, err := s.session.CheckFile(filename, overlay)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources := sourceCache{files: overlay}
+	diagnostics := []diagnostic{}
+	var actions []codeAction
+	for _, warn := range warnings {
+		d := diagnostic{
+			Range:    sources.textRange(fset, warn.Node.Pos(), warn.Node.End()),
+			Severity: lspSeverity(warn.Severity),
+			Code:     warn.Info.Name,
+			Source:   s.name,
+			Message:  warn.Text,
+		}
+		diagnostics = append(diagnostics, d)
+		if edit, ok := sources.workspaceEdit(fset, warn.Fix); ok {
+			actions = append(actions, codeAction{
+				Title:       fmt.Sprintf("Apply %s fix", warn.Info.Name),
+				Kind:        "quickfix",
+				Diagnostics: []diagnostic{d},
+				Edit:        edit,
+			})
+		}
+	}
+	return diagnostics, actions, nil
+}
+
+func lspSeverity(s lintpack.Severity) int {
+	switch s {
+	case lintpack.SeverityError:
+		return severityError
+	case lintpack.SeverityWarning:
+		return severityWarning
+	case lintpack.SeverityInfo:
+		return severityInformation
+	default:
+		return severityHint
+	}
+}
+
+// sourceCache holds file contents that are used to convert
+// byte offsets into UTF-16 positions.
+type sourceCache struct {
+	// files maps file names to their contents.
+	// Files are read on demand and nil is stored on errors.
+	files map[string][]byte
+}
+
+func (cache sourceCache) get(filename string) []byte {
+	src, ok := cache.files[filename]
+	if !ok {
+		src, _ = ioutil.ReadFile(filename)
+		cache.files[filename] = src
+	}
+	return src
+}
+
+// position converts pos to the LSP position.
+// Byte columns are used if the file can't be read.
+func (cache sourceCache) position(fset *token.FileSet, pos token.Pos) position {
+	p := fset.PositionFor(pos, false)
+	src := cache.get(p.Filename)
+	lineStart := p.Offset - (p.Column - 1)
+	if lineStart < 0 || p.Offset > len(src) {
+		return position{Line: p.Line - 1, Character: p.Column - 1}
+	}
+	return position{Line: p.Line - 1, Character: utf16Len(src[lineStart:p.Offset])}
+}
+
+func (cache sourceCache) textRange(fset *token.FileSet, pos, end token.Pos) textRange {
+	return textRange{
+		Start: cache.position(fset, pos),
+		End:   cache.position(fset, end),
+	}
+}
+
+// workspaceEdit converts a checker fix to the LSP edit.
+// Returns false if there is no fix or it has invalid positions.
+func (cache sourceCache) workspaceEdit(fset *token.FileSet, fix []lintpack.TextEdit) (workspaceEdit, bool) {
+	if len(fix) == 0 {
+		return workspaceEdit{}, false
+	}
+	edit := workspaceEdit{Changes: make(map[string][]textEdit)}
+	for _, e := range fix {
+		if !e.Pos.IsValid() || !e.End.IsValid() {
+			return workspaceEdit{}, false
+		}
+		uri := filenameURI(fset.PositionFor(e.Pos, false).Filename)
+		edit.Changes[uri] = append(edit.Changes[uri], textEdit{
+			Range:   cache.textRange(fset, e.Pos, e.End),
+			NewText: e.NewText,
+		})
+	}
+	return edit, true
+}
+
+// utf16Len returns the number of UTF-16 code units in src.
+func utf16Len(src []byte) int {
+	n := 0
+	for len(src) != 0 {
+		r, size := utf8.DecodeRune(src)
+		src = src[size:]
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return n
+}
@@ -0,0 +1,302 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-lintpack/lintpack/linter/lintmain/internal/check"
+)
+
+// Main implements sub-command entry point.
+//
+// The language server communicates over stdin and stdout,
+// stderr is used for the debug output.
+func Main(linterName, linterVersion string) {
+	session, err := check.NewSession(linterName, linterVersion)
+	if err != nil {
+		log.Fatalf("init: %v", err)
+	}
+	s := &server{
+		name:    linterName,
+		version: linterVersion,
+		session: session,
+		docs:    make(map[string]*document),
+		fixes:   make(map[string][]codeAction),
+		verbose: session.Verbose(),
+	}
+
+	exitCode, err := s.serve(os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatalf("serve: %v", err)
+	}
+	os.Exit(exitCode)
+}
+
+// server is a language server that publishes warnings
+// of the enabled checkers as diagnostics.
+type server struct {
+	// name and version describe the linter.
+	name    string
+	version string
+
+	out io.Writer
+
+	// session checks the documents packages using
+	// the check sub-command settings.
+	session *check.Session
+
+	// docs maps file names to the open documents.
+	docs map[string]*document
+
+	// fixes maps document URIs to code actions that
+	// apply fixes suggested for the published diagnostics.
+	fixes map[string][]codeAction
+
+	initialized bool
+	shutdown    bool
+	verbose     bool
+}
+
+// document is a text document opened in the editor.
+type document struct {
+	uri     string
+	version int
+	text    []byte
+}
+
+// serve handles messages until the exit notification or the end of input.
+// Returns the process exit code.
+func (s *server) serve(in io.Reader, out io.Writer) (int, error) {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		msg, err := readMessage(r)
+		if err == io.EOF {
+			return 1, nil // Client went away without the exit notification
+		}
+		if rerr, ok := err.(*responseError); ok {
+			if err := s.reply(nil, nil, rerr); err != nil {
+				return 1, err
+			}
+			continue
+		}
+		if err != nil {
+			return 1, err
+		}
+
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return 0, nil
+			}
+			return 1, nil
+		}
+		if err := s.handle(msg); err != nil {
+			return 1, err
+		}
+	}
+}
+
+// handle dispatches the message to its handler and sends a response
+// if the message is a request. Returns only the output errors.
+func (s *server) handle(msg *message) error {
+	if s.verbose {
+		log.Printf("\tdebug: %s", msg.Method)
+	}
+
+	isRequest := msg.ID != nil
+	if !s.initialized && msg.Method != "initialize" {
+		if isRequest {
+			return s.reply(msg.ID, nil, &responseError{
+				Code:    codeServerNotInitialized,
+				Message: "server is not initialized",
+			})
+		}
+		return nil
+	}
+
+	var result interface{}
+	var err error
+	switch msg.Method {
+	case "initialize":
+		s.initialized = true
+		result = initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncOptions{
+					OpenClose: true,
+					Change:    textDocumentSyncFull,
+					Save:      true,
+				},
+				CodeActionProvider: true,
+			},
+			ServerInfo: serverInfo{Name: s.name, Version: s.version},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = unmarshalParams(msg, &params); err == nil {
+			err = s.didOpen(params)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = unmarshalParams(msg, &params); err == nil {
+			err = s.didChange(params)
+		}
+	case "textDocument/didSave":
+		var params didSaveParams
+		if err = unmarshalParams(msg, &params); err == nil {
+			err = s.lint(params.TextDocument.URI)
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err = unmarshalParams(msg, &params); err == nil {
+			err = s.didClose(params)
+		}
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err = unmarshalParams(msg, &params); err == nil {
+			result = s.codeActions(params)
+		}
+	default:
+		if isRequest {
+			err = &responseError{
+				Code:    codeMethodNotFound,
+				Message: fmt.Sprintf("unsupported method %q", msg.Method),
+			}
+		}
+		// Unsupported notifications, like $/cancelRequest, are ignored.
+	}
+
+	var rerr *responseError
+	switch e := err.(type) {
+	case nil:
+	case *responseError:
+		rerr = e
+	default:
+		// Handlers report protocol errors as *responseError,
+		// other errors are output errors.
+		return err
+	}
+	if isRequest {
+		return s.reply(msg.ID, result, rerr)
+	}
+	if rerr != nil && s.verbose {
+		log.Printf("\tdebug: %s: %v", msg.Method, rerr)
+	}
+	return nil
+}
+
+func unmarshalParams(msg *message, params interface{}) error {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// reply sends a response to the request with the given id.
+func (s *server) reply(id *json.RawMessage, result interface{}, rerr *responseError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = data
+	}
+	return writeMessage(s.out, resp)
+}
+
+// notify sends a notification to the client.
+func (s *server) notify(method string, params interface{}) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *server) didOpen(params didOpenParams) error {
+	doc := params.TextDocument
+	filename, err := uriFilename(doc.URI)
+	if err != nil {
+		return err
+	}
+	s.docs[filename] = &document{
+		uri:     doc.URI,
+		version: doc.Version,
+		text:    []byte(doc.Text),
+	}
+	return s.lint(doc.URI)
+}
+
+func (s *server) didChange(params didChangeParams) error {
+	filename, err := uriFilename(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	doc := s.docs[filename]
+	if doc == nil || len(params.ContentChanges) == 0 {
+		return nil
+	}
+	// With the full sync, the last change holds the whole document.
+	doc.text = []byte(params.ContentChanges[len(params.ContentChanges)-1].Text)
+	doc.version = params.TextDocument.Version
+	return s.lint(doc.uri)
+}
+
+func (s *server) didClose(params didCloseParams) error {
+	filename, err := uriFilename(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	delete(s.docs, filename)
+	delete(s.fixes, params.TextDocument.URI)
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         params.TextDocument.URI,
+		Diagnostics: []diagnostic{},
+	})
+}
+
+// codeActions returns fixes for the diagnostics inside the range.
+func (s *server) codeActions(params codeActionParams) []codeAction {
+	actions := []codeAction{}
+	for _, action := range s.fixes[params.TextDocument.URI] {
+		if action.Diagnostics[0].Range.intersects(params.Range) {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// uriFilename converts a file URI to a file path.
+// Errors are reported as *responseError with invalid params code.
+func uriFilename(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	if u.Scheme != "file" {
+		return "", &responseError{
+			Code:    codeInvalidParams,
+			Message: fmt.Sprintf("%s: unsupported URI scheme", uri),
+		}
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/") // Like /C:/dir
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// filenameURI converts an absolute file path to a file URI.
+func filenameURI(filename string) string {
+	path := filepath.ToSlash(filename)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows paths, like C:/dir
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
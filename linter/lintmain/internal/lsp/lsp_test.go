@@ -0,0 +1,283 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-lintpack/lintpack"
+	"github.com/go-lintpack/lintpack/linter/lintmain/internal/check"
+)
+
+func TestServe(t *testing.T) {
+	var in strings.Builder
+	send := func(msg string) {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"textDocument/codeAction","params":{}}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{}}`)
+	send(`{"jsonrpc":"2.0","method":"initialized","params":{}}`)
+	send(`{"jsonrpc":"2.0","id":3,"method":"workspace/symbol","params":{}}`)
+	send(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.txt","version":1,"text":"x"}}}`)
+	send(`{"jsonrpc":"2.0","id":"4","method":"textDocument/codeAction","params":{"textDocument":{"uri":"file:///a.go"}}}`)
+	send(`{bad json`)
+	send(`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`)
+	send(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	s := &server{
+		name:  "linter",
+		docs:  make(map[string]*document),
+		fixes: make(map[string][]codeAction),
+	}
+	var out strings.Builder
+	code, err := s.serve(strings.NewReader(in.String()), &out)
+	if err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if code != 0 {
+		t.Errorf("exit code: have %d, want 0", code)
+	}
+
+	var have []string
+	r := bufio.NewReader(strings.NewReader(out.String()))
+	for {
+		var resp struct {
+			ID     json.RawMessage `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *responseError  `json:"error"`
+		}
+		if err := readJSON(r, &resp); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Error != nil {
+			have = append(have, fmt.Sprintf("%s: error %d", resp.ID, resp.Error.Code))
+			continue
+		}
+		have = append(have, fmt.Sprintf("%s: %s", resp.ID, resp.Result))
+	}
+
+	want := []string{
+		`1: error -32002`,
+		`2: {"capabilities":{"textDocumentSync":{"openClose":true,"change":1,"save":true},"codeActionProvider":true},"serverInfo":{"name":"linter"}}`,
+		`3: error -32601`,
+		`"4": []`,
+		`null: error -32700`,
+		`5: null`,
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("responses mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+}
+
+// readJSON reads a framed message body into v.
+func readJSON(r *bufio.Reader, v interface{}) error {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return err
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func TestPosition(t *testing.T) {
+	src := "package a\n\n// héllo 😀 x\n"
+	fset := token.NewFileSet()
+	f := fset.AddFile("/a.go", -1, len(src))
+	f.SetLinesForContent([]byte(src))
+	cache := sourceCache{files: map[string][]byte{"/a.go": []byte(src)}}
+
+	tests := []struct {
+		offset int
+		want   position
+	}{
+		{0, position{Line: 0, Character: 0}},
+		{strings.Index(src, "//"), position{Line: 2, Character: 0}},
+		{strings.Index(src, "😀"), position{Line: 2, Character: 9}},
+		{strings.Index(src, "x"), position{Line: 2, Character: 12}},
+	}
+	for _, test := range tests {
+		have := cache.position(fset, f.Pos(test.offset))
+		if have != test.want {
+			t.Errorf("offset %d: have %+v, want %+v", test.offset, have, test.want)
+		}
+	}
+}
+
+func TestCodeActions(t *testing.T) {
+	newAction := func(line int) codeAction {
+		r := textRange{
+			Start: position{Line: line, Character: 2},
+			End:   position{Line: line, Character: 10},
+		}
+		return codeAction{Title: fmt.Sprint(line), Diagnostics: []diagnostic{{Range: r}}}
+	}
+	s := &server{fixes: map[string][]codeAction{
+		"file:///a.go": {newAction(1), newAction(5), newAction(7)},
+	}}
+
+	params := codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: "file:///a.go"},
+		Range: textRange{
+			Start: position{Line: 5, Character: 10},
+			End:   position{Line: 6, Character: 0},
+		},
+	}
+	var have []string
+	for _, action := range s.codeActions(params) {
+		have = append(have, action.Title)
+	}
+	if want := []string{"5"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("code actions: have %v, want %v", have, want)
+	}
+}
+
+func TestURIFilename(t *testing.T) {
+	for _, filename := range []string{"/home/user/a.go", "/home/user/dir with spaces/a.go"} {
+		uri := filenameURI(filename)
+		have, err := uriFilename(uri)
+		if err != nil {
+			t.Errorf("%s: %v", uri, err)
+			continue
+		}
+		if have != filename {
+			t.Errorf("%s: have %q, want %q", uri, have, filename)
+		}
+	}
+	if _, err := uriFilename("untitled:Untitled-1"); err == nil {
+		t.Errorf("expected an error for non-file URI")
+	}
+}
+
+// callWalker reports every call expression and suggests its removal.
+type callWalker struct {
+	ctx *lintpack.CheckerContext
+}
+
+func (w *callWalker) WalkFile(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			w.ctx.WarnFixable(call, []lintpack.TextEdit{
+				{Pos: call.Pos(), End: call.End(), NewText: "_ = 0"},
+			}, "call found")
+		}
+		return true
+	})
+}
+
+func TestCheckDocument(t *testing.T) {
+	coll := &lintpack.CheckerCollection{URL: "example.com"}
+	coll.AddChecker(&lintpack.CheckerInfo{Name: "lspTest", Summary: "Example"},
+		func(ctx *lintpack.CheckerContext) lintpack.FileWalker {
+			return &callWalker{ctx: ctx}
+		})
+
+	dir, err := ioutil.TempDir("", "lintpack-lsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, src string) string {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	write("go.mod", "module example.com/a\n")
+	filename := write("a.go", "package a\n")
+	write("b.go", "package a\n\nfunc g() { println() }\n")
+
+	session, err := check.NewSession("lspTestLinter", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{
+		name:    "linter",
+		session: session,
+		docs:    make(map[string]*document),
+		fixes:   make(map[string][]codeAction),
+	}
+	var out strings.Builder
+	s.out = &out
+
+	// The document is only checked with its editor contents,
+	// warnings of the other package files are not published.
+	uri := filenameURI(filename)
+	text := "package a\n\nfunc f() {\n\tprintln(\"ж\")\n\tprintln() //lintpack:ignore lspTest\n}\n"
+	err = s.didOpen(didOpenParams{
+		TextDocument: textDocumentItem{URI: uri, Version: 2, Text: text},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var notification struct {
+		Method string                   `json:"method"`
+		Params publishDiagnosticsParams `json:"params"`
+	}
+	if err := readJSON(bufio.NewReader(strings.NewReader(out.String())), &notification); err != nil {
+		t.Fatal(err)
+	}
+	if notification.Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("unexpected %s notification", notification.Method)
+	}
+	version := 2
+	start := position{Line: 3, Character: 1}
+	end := position{Line: 3, Character: 13} // "ж" is 2 bytes and 1 code unit
+	want := publishDiagnosticsParams{
+		URI:     uri,
+		Version: &version,
+		Diagnostics: []diagnostic{{
+			Range:    textRange{Start: start, End: end},
+			Severity: severityWarning,
+			Code:     "lspTest",
+			Source:   "linter",
+			Message:  "call found",
+		}},
+	}
+	if !reflect.DeepEqual(notification.Params, want) {
+		t.Errorf("diagnostics mismatch:\nhave: %+v\nwant: %+v", notification.Params, want)
+	}
+
+	actions := s.codeActions(codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Range:        textRange{Start: start, End: start},
+	})
+	wantEdit := workspaceEdit{Changes: map[string][]textEdit{
+		uri: {{Range: textRange{Start: start, End: end}, NewText: "_ = 0"}},
+	}}
+	if len(actions) != 1 || !reflect.DeepEqual(actions[0].Edit, wantEdit) {
+		t.Errorf("code actions mismatch:\nhave: %+v\nwant: one action with %+v", actions, wantEdit)
+	}
+
+	// Fixes are dropped if the package can't be checked anymore.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.lint(uri); err != nil {
+		t.Fatal(err)
+	}
+	if fixes, ok := s.fixes[uri]; ok {
+		t.Errorf("stale fixes are not removed: %+v", fixes)
+	}
+}
@@ -0,0 +1,225 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC 2.0 error codes used by the server.
+const (
+	codeParseError           = -32700
+	codeInvalidParams        = -32602
+	codeMethodNotFound       = -32601
+	codeServerNotInitialized = -32002
+)
+
+// message is a JSON-RPC 2.0 request or notification.
+// Requests have an ID, notifications don't.
+type message struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+// response is a JSON-RPC 2.0 response.
+// Exactly one of Result and Error is set.
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+// notification is a JSON-RPC 2.0 notification sent by the server.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string { return e.Message }
+
+// readMessage reads a single message that is framed
+// by the Content-Length header, as described by the LSP base protocol.
+//
+// Malformed JSON is reported as *responseError,
+// so the caller can reply with a parse error and continue.
+func readMessage(r *bufio.Reader) (*message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+	return &msg, nil
+}
+
+// writeMessage writes a response or notification
+// with the Content-Length header.
+func writeMessage(w io.Writer, msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// LSP structures.
+// Only the subset that is used by the server is described.
+// See https://microsoft.github.io/language-server-protocol/specification.
+type (
+	initializeResult struct {
+		Capabilities serverCapabilities `json:"capabilities"`
+		ServerInfo   serverInfo         `json:"serverInfo"`
+	}
+
+	serverCapabilities struct {
+		TextDocumentSync   textDocumentSyncOptions `json:"textDocumentSync"`
+		CodeActionProvider bool                    `json:"codeActionProvider"`
+	}
+
+	textDocumentSyncOptions struct {
+		OpenClose bool `json:"openClose"`
+		Change    int  `json:"change"`
+		Save      bool `json:"save"`
+	}
+
+	serverInfo struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+
+	textDocumentItem struct {
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+		Text    string `json:"text"`
+	}
+
+	textDocumentIdentifier struct {
+		URI string `json:"uri"`
+	}
+
+	versionedTextDocumentIdentifier struct {
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+	}
+
+	didOpenParams struct {
+		TextDocument textDocumentItem `json:"textDocument"`
+	}
+
+	didChangeParams struct {
+		TextDocument   versionedTextDocumentIdentifier `json:"textDocument"`
+		ContentChanges []contentChangeEvent            `json:"contentChanges"`
+	}
+
+	// contentChangeEvent is a full document change,
+	// since the server only supports the full sync.
+	contentChangeEvent struct {
+		Text string `json:"text"`
+	}
+
+	didSaveParams struct {
+		TextDocument textDocumentIdentifier `json:"textDocument"`
+	}
+
+	didCloseParams struct {
+		TextDocument textDocumentIdentifier `json:"textDocument"`
+	}
+
+	// position is a zero-based line and UTF-16 code units offset.
+	position struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+
+	textRange struct {
+		Start position `json:"start"`
+		End   position `json:"end"`
+	}
+
+	diagnostic struct {
+		Range    textRange `json:"range"`
+		Severity int       `json:"severity"`
+		Code     string    `json:"code"`
+		Source   string    `json:"source"`
+		Message  string    `json:"message"`
+	}
+
+	publishDiagnosticsParams struct {
+		URI         string       `json:"uri"`
+		Version     *int         `json:"version,omitempty"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+
+	codeActionParams struct {
+		TextDocument textDocumentIdentifier `json:"textDocument"`
+		Range        textRange              `json:"range"`
+	}
+
+	codeAction struct {
+		Title       string        `json:"title"`
+		Kind        string        `json:"kind"`
+		Diagnostics []diagnostic  `json:"diagnostics"`
+		Edit        workspaceEdit `json:"edit"`
+	}
+
+	workspaceEdit struct {
+		Changes map[string][]textEdit `json:"changes"`
+	}
+
+	textEdit struct {
+		Range   textRange `json:"range"`
+		NewText string    `json:"newText"`
+	}
+)
+
+// LSP diagnostic severities.
+const (
+	severityError       = 1
+	severityWarning     = 2
+	severityInformation = 3
+	severityHint        = 4
+)
+
+// textDocumentSyncFull means that documents are synced
+// by always sending their full content.
+const textDocumentSyncFull = 1
+
+// before reports whether p is located before other.
+func (p position) before(other position) bool {
+	return p.Line < other.Line ||
+		p.Line == other.Line && p.Character < other.Character
+}
+
+// intersects reports whether the ranges have common positions.
+// Ranges are inclusive, so adjacent ranges intersect.
+func (r textRange) intersects(other textRange) bool {
+	return !r.End.before(other.Start) && !other.End.before(r.Start)
+}
+
+// isGoFile reports whether uri refers to a Go source file.
+func isGoFile(uri string) bool {
+	return strings.HasSuffix(uri, ".go")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-lintpack/lintpack/internal/cmdutil"
 	"github.com/go-lintpack/lintpack/linter/lintmain/internal/check"
 	"github.com/go-lintpack/lintpack/linter/lintmain/internal/lintdoc"
+	"github.com/go-lintpack/lintpack/linter/lintmain/internal/lsp"
 )
 
 // Config is used to parametrize the linter.
@@ -33,7 +34,7 @@ func Run(cfg Config) {
 
 	subCommands := []*cmdutil.SubCommand{
 		{
-			Main:  check.Main,
+			Main:  func() { check.Main(cfg.Name, cfg.Version) },
 			Name:  "check",
 			Short: "run linter over specified targets",
 			Examples: makeExamples(
@@ -42,6 +43,15 @@ func Run(cfg Config) {
 				"%s check -v -enable='#diagnostic' -disable='#experimental,#opinionated' ./...",
 			),
 		},
+		{
+			Main:  func() { lsp.Main(cfg.Name, cfg.Version) },
+			Name:  "lsp",
+			Short: "run language server over stdio",
+			Examples: makeExamples(
+				"%s lsp",
+				"%s lsp -enable='#diagnostic' -v",
+			),
+		},
 		{
 			Main:     printVersion,
 			Name:     "version",
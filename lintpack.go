@@ -5,6 +5,9 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/go-toolsmith/astfmt"
 )
@@ -13,11 +16,95 @@ import (
 type CheckerCollection struct {
 	// URL is a link for a main source of information on the collection.
 	URL string
+
+	// StrictDocumentation enables additional documentation checks
+	// for the collection checkers during their registration.
+	//
+	// In strict mode, Before and After snippets are mandatory and
+	// must be valid Go statements or declarations, Summary must start
+	// with a capital letter and Before must differ from After.
+	StrictDocumentation bool
+
+	// Registry is a registry the collection checkers are added to.
+	// Optional, the default registry is used if nil.
+	Registry *Registry
+}
+
+// Registry holds registered checkers.
+//
+// Registries are independent from each other, so checkers with
+// the same name can be registered in different registries.
+// Package-level functions like GetCheckersInfo and NewChecker
+// operate on the default registry.
+type Registry struct {
+	mu sync.RWMutex
+
+	// prototypes is a set of registered checkers that are not yet instantiated.
+	prototypes map[string]checkerProto
+}
+
+// NewRegistry returns a new empty checkers registry.
+func NewRegistry() *Registry {
+	return &Registry{prototypes: make(map[string]checkerProto)}
+}
+
+// AddChecker registers a new checker into the registry.
+// It's like CheckerCollection.AddChecker, but returns an error
+// instead of panicking if info is invalid or the checker name is taken.
+//
+// info.Collection must be set by the caller, it's an error otherwise.
+func (r *Registry) AddChecker(info *CheckerInfo, constructor func(*CheckerContext) FileWalker) error {
+	return r.addChecker(info, func(ctx *CheckerContext) (FileWalker, PackageWalker) {
+		w := constructor(ctx)
+		pw, _ := w.(PackageWalker)
+		return w, pw
+	})
+}
+
+// AddPackageChecker registers a new package-level checker into the registry.
+// It's like CheckerCollection.AddPackageChecker, but returns an error
+// instead of panicking if info is invalid or the checker name is taken.
+//
+// info.Collection must be set by the caller, it's an error otherwise.
+func (r *Registry) AddPackageChecker(info *CheckerInfo, constructor func(*CheckerContext) PackageWalker) error {
+	return r.addChecker(info, func(ctx *CheckerContext) (FileWalker, PackageWalker) {
+		return nil, constructor(ctx)
+	})
+}
+
+// GetCheckersInfo returns a checkers info list for all checkers
+// registered in r. The slice is sorted by a checker name.
+func (r *Registry) GetCheckersInfo() []*CheckerInfo {
+	return r.getCheckersInfo()
+}
+
+// NewChecker returns initialized checker identified by an info.
+// info must be non-nil.
+// Panics if info describes a checker that is not registered in r.
+func (r *Registry) NewChecker(ctx *Context, info *CheckerInfo) *Checker {
+	return r.newChecker(ctx, info, nil)
+}
+
+// NewCheckerWithParams is like NewChecker, but the checker gets its own
+// param values instead of the shared CheckerInfo.Params values.
+//
+// values maps param names to their values, other params keep the info values.
+// Panics if values refer to unknown params or have invalid types.
+func (r *Registry) NewCheckerWithParams(ctx *Context, info *CheckerInfo, values map[string]interface{}) *Checker {
+	return r.newChecker(ctx, info, values)
 }
 
-// AddChecker registers a new checker into a checkers pool.
+// registry returns a registry the collection checkers are added to.
+func (coll *CheckerCollection) registry() *Registry {
+	if coll.Registry != nil {
+		return coll.Registry
+	}
+	return defaultRegistry
+}
+
+// AddChecker registers a new checker into the collection registry.
 // Constructor is used to create a new checker instance.
-// Checker name (defined in CheckerInfo.Name) must be unique.
+// Checker name (defined in CheckerInfo.Name) must be unique within the registry.
 //
 // CheckerInfo.Collection is automatically set to the coll (the receiver).
 //
@@ -28,10 +115,30 @@ func (coll *CheckerCollection) AddChecker(info *CheckerInfo, constructor func(*C
 		panic(fmt.Sprintf("adding checker to a nil collection"))
 	}
 	info.Collection = coll
-	addChecker(info, constructor)
+	if err := coll.registry().AddChecker(info, constructor); err != nil {
+		panic(err)
+	}
+}
+
+// AddPackageChecker registers a new package-level checker into the collection registry.
+// It's like AddChecker, but for checkers that only implement PackageWalker.
+//
+// Checkers registered by AddChecker can also implement PackageWalker
+// in addition to FileWalker.
+func (coll *CheckerCollection) AddPackageChecker(info *CheckerInfo, constructor func(*CheckerContext) PackageWalker) {
+	if coll == nil {
+		panic(fmt.Sprintf("adding checker to a nil collection"))
+	}
+	info.Collection = coll
+	if err := coll.registry().AddPackageChecker(info, constructor); err != nil {
+		panic(err)
+	}
 }
 
 // CheckerParam describes a single checker customizable parameter.
+//
+// CheckerParam implements flag.Value interface, so it can be
+// bound to a command-line flag directly.
 type CheckerParam struct {
 	// Value holds parameter bound value.
 	// It might be overwritten by the integrating linter.
@@ -40,10 +147,27 @@ type CheckerParam struct {
 	//	- int
 	//	- bool
 	//	- string
+	//	- float64
+	//	- time.Duration
+	//	- []string
+	//	- *regexp.Regexp
 	Value interface{}
 
 	// Usage gives an overview about what parameter does.
 	Usage string
+
+	// Min is an optional inclusive lower bound for
+	// int, float64 and time.Duration params.
+	// Must have the same type as Value.
+	Min interface{}
+
+	// Max is an optional inclusive upper bound for
+	// int, float64 and time.Duration params.
+	// Must have the same type as Value.
+	Max interface{}
+
+	// Enum is an optional list of allowed values for string params.
+	Enum []string
 }
 
 // CheckerParams holds all checker-specific parameters.
@@ -60,6 +184,23 @@ func (params CheckerParams) Bool(pname string) bool { return params[pname].Value
 // String lookups pname key in underlying map and type-asserts it to string.
 func (params CheckerParams) String(pname string) string { return params[pname].Value.(string) }
 
+// Float64 lookups pname key in underlying map and type-asserts it to float64.
+func (params CheckerParams) Float64(pname string) float64 { return params[pname].Value.(float64) }
+
+// Duration lookups pname key in underlying map and type-asserts it to time.Duration.
+func (params CheckerParams) Duration(pname string) time.Duration {
+	return params[pname].Value.(time.Duration)
+}
+
+// Strings lookups pname key in underlying map and type-asserts it to []string.
+func (params CheckerParams) Strings(pname string) []string { return params[pname].Value.([]string) }
+
+// Regexp lookups pname key in underlying map and type-asserts it to *regexp.Regexp.
+// Returns nil if regexp value is not set.
+func (params CheckerParams) Regexp(pname string) *regexp.Regexp {
+	return params[pname].Value.(*regexp.Regexp)
+}
+
 // CheckerInfo holds checker metadata and structured documentation.
 type CheckerInfo struct {
 	// Name is a checker name.
@@ -72,6 +213,19 @@ type CheckerInfo struct {
 	// Params declares checker-specific parameters. Optional.
 	Params CheckerParams
 
+	// Resources lists shared resources the checker uses. Optional.
+	// Resources that are not listed can't be obtained from CheckerContext.
+	Resources []*Resource
+
+	// FactTypes lists types of the facts the checker exports and imports.
+	// Optional, but required to pass facts between packages when
+	// the checker is run as a go/analysis analyzer, see lintanalysis.
+	FactTypes []Fact
+
+	// Severity is a default severity of the checker warnings.
+	// Optional, SeverityWarning is used if not set.
+	Severity Severity
+
 	// Summary is a short one sentence description.
 	// Should not end with a period.
 	Summary string
@@ -97,7 +251,7 @@ type CheckerInfo struct {
 //
 // Info objects can be used to instantiate checkers with NewChecker function.
 func GetCheckersInfo() []*CheckerInfo {
-	return getCheckersInfo()
+	return defaultRegistry.GetCheckersInfo()
 }
 
 // HasTag reports whether checker described by the info has specified tag.
@@ -117,13 +271,35 @@ type Checker struct {
 
 	ctx CheckerContext
 
-	fileWalker FileWalker
+	fileWalker    FileWalker
+	packageWalker PackageWalker
+}
+
+// Params returns param values of the checker instance.
+func (c *Checker) Params() CheckerParams {
+	return c.ctx.Params
 }
 
 // Check runs rule checker over file f.
+//
+// Does nothing for checkers that don't implement FileWalker.
 func (c *Checker) Check(f *ast.File) []Warning {
 	c.ctx.warnings = c.ctx.warnings[:0]
-	c.fileWalker.WalkFile(f)
+	if c.fileWalker != nil {
+		c.fileWalker.WalkFile(f)
+	}
+	return c.ctx.warnings
+}
+
+// CheckPackage runs rule checker over all package files.
+// Should be called after Check was executed for every package file.
+//
+// Does nothing for checkers that don't implement PackageWalker.
+func (c *Checker) CheckPackage(files []*ast.File) []Warning {
+	c.ctx.warnings = c.ctx.warnings[:0]
+	if c.packageWalker != nil {
+		c.packageWalker.WalkPackage(files)
+	}
 	return c.ctx.warnings
 }
 
@@ -135,13 +311,51 @@ type Warning struct {
 
 	// Text is warning message without source location info.
 	Text string
+
+	// Severity describes how serious the issue is.
+	Severity Severity
+
+	// Fix is an optional list of edits that resolve the issue.
+	// Edits must not overlap each other and must belong to the same file.
+	Fix []TextEdit
+}
+
+// TextEdit describes a single source code modification.
+//
+// The [Pos, End) range is replaced by the NewText.
+// Pos == End describes an insertion, empty NewText describes a deletion.
+type TextEdit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// ReplaceNode returns an edit that replaces node source code with text.
+func ReplaceNode(node ast.Node, text string) TextEdit {
+	return TextEdit{Pos: node.Pos(), End: node.End(), NewText: text}
+}
+
+// InsertText returns an edit that inserts text at the pos.
+func InsertText(pos token.Pos, text string) TextEdit {
+	return TextEdit{Pos: pos, End: pos, NewText: text}
+}
+
+// DeleteRange returns an edit that removes [pos, end) source code range.
+func DeleteRange(pos, end token.Pos) TextEdit {
+	return TextEdit{Pos: pos, End: end}
 }
 
 // NewChecker returns initialized checker identified by an info.
 // info must be non-nil.
 // Panics if info describes a checker that was not properly registered.
 func NewChecker(ctx *Context, info *CheckerInfo) *Checker {
-	return newChecker(ctx, info)
+	return defaultRegistry.NewChecker(ctx, info)
+}
+
+// NewCheckerWithParams returns initialized checker with its own param values.
+// See Registry.NewCheckerWithParams.
+func NewCheckerWithParams(ctx *Context, info *CheckerInfo, values map[string]interface{}) *Checker {
+	return defaultRegistry.NewCheckerWithParams(ctx, info, values)
 }
 
 // Context is a readonly state shared among every checker.
@@ -162,12 +376,17 @@ type Context struct {
 	// Filename is a currently checked file name.
 	Filename string
 
+	// Files holds all source files of the package being checked.
+	Files []*ast.File
+
 	// Require records what optional resources are required
 	// by the checkers set that use this context.
 	//
 	// Every require fields makes associated context field
 	// to be properly initialized.
 	// For example, Context.require.PkgObjects => Context.PkgObjects.
+	//
+	// See Resource for a more general way to share analysis data.
 	Require struct {
 		PkgObjects bool
 		PkgRenames bool
@@ -180,6 +399,14 @@ type Context struct {
 	// Contains no entries for packages that were imported without
 	// explicit local names.
 	PkgRenames map[string]string
+
+	// facts holds facts exported by the checkers.
+	// Preserved between SetPackageInfo calls.
+	facts factStore
+
+	// resources holds computed resource values.
+	// Reset by SetPackageInfo calls.
+	resources resourceCache
 }
 
 // NewContext returns new shared context to be used by every checker.
@@ -205,6 +432,15 @@ func (c *Context) SetPackageInfo(info *types.Info, pkg *types.Package) {
 		*c.TypesInfo = *info
 	}
 	c.Pkg = pkg
+	c.resources.reset()
+}
+
+// SetPackageFiles sets the list of source files of the package being checked.
+//
+// Should be called for every package being checked, after SetPackageInfo.
+// Checkers that need a whole package view rely on it.
+func (c *Context) SetPackageFiles(files []*ast.File) {
+	c.Files = files
 }
 
 // SetFileInfo sets file-related metadata.
@@ -225,6 +461,17 @@ func (c *Context) SetFileInfo(name string, f *ast.File) {
 type CheckerContext struct {
 	*Context
 
+	// Params holds param values of the checker instance.
+	// Unless the checker was created by NewCheckerWithParams,
+	// these are the CheckerInfo.Params.
+	//
+	// Checkers should read their params from here,
+	// so per-instance values are respected.
+	Params CheckerParams
+
+	// info is an info object of the checker that owns the context.
+	info *CheckerInfo
+
 	// printer used to format warning text.
 	printer *astfmt.Printer
 
@@ -232,10 +479,34 @@ type CheckerContext struct {
 }
 
 // Warn adds a Warning to checker output.
+// Warning severity is inherited from the checker info.
 func (ctx *CheckerContext) Warn(node ast.Node, format string, args ...interface{}) {
+	ctx.WarnSeverity(ctx.info.Severity, node, format, args...)
+}
+
+// WarnSeverity is like Warn, but overrides the default checker severity.
+// Panics if severity is not one of the known severity levels.
+func (ctx *CheckerContext) WarnSeverity(severity Severity, node ast.Node, format string, args ...interface{}) {
+	if !severity.IsValid() {
+		panic(fmt.Sprintf("%s: invalid warning severity %v", ctx.info.Name, severity))
+	}
 	ctx.warnings = append(ctx.warnings, Warning{
-		Text: ctx.printer.Sprintf(format, args...),
-		Node: node,
+		Text:     ctx.printer.Sprintf(format, args...),
+		Node:     node,
+		Severity: severity,
+	})
+}
+
+// WarnFixable adds a Warning with a suggested fix to checker output.
+//
+// The fix is applied by the integrating linter on demand,
+// for example, by the `check -fix` command.
+func (ctx *CheckerContext) WarnFixable(node ast.Node, fix []TextEdit, format string, args ...interface{}) {
+	ctx.warnings = append(ctx.warnings, Warning{
+		Text:     ctx.printer.Sprintf(format, args...),
+		Node:     node,
+		Severity: ctx.info.Severity,
+		Fix:      fix,
 	})
 }
 
@@ -246,3 +517,14 @@ func (ctx *CheckerContext) Warn(node ast.Node, format string, args ...interface{
 type FileWalker interface {
 	WalkFile(*ast.File)
 }
+
+// PackageWalker is an interface for checkers that need to see
+// the whole package at once.
+//
+// The WalkPackage method is executed once for every package
+// being checked, after all package files were walked.
+// All package files are passed, including the ones that are
+// excluded from checking; warnings for such files are ignored.
+type PackageWalker interface {
+	WalkPackage([]*ast.File)
+}
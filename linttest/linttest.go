@@ -33,6 +33,7 @@ func saneCheckersList(t *testing.T) []*lintpack.CheckerInfo {
 					TypesInfo: pkg.TypesInfo,
 					Pkg:       pkg.Types,
 				}
+				ctx.SetPackageFiles(pkg.Syntax)
 				c := lintpack.NewChecker(ctx, info)
 				defer func() {
 					r := recover()
@@ -46,6 +47,7 @@ func saneCheckersList(t *testing.T) []*lintpack.CheckerInfo {
 					ctx.SetFileInfo(getFilename(fset, f), f)
 					_ = c.Check(f)
 				}
+				_ = c.CheckPackage(pkg.Syntax)
 			}
 		})
 	}
@@ -85,16 +87,50 @@ func TestCheckers(t *testing.T) {
 					TypesInfo: pkg.TypesInfo,
 					Pkg:       pkg.Types,
 				}
+				ctx.SetPackageFiles(pkg.Syntax)
 				c := lintpack.NewChecker(ctx, info)
-				for _, f := range pkg.Syntax {
-					checkFile(t, c, ctx, f)
-				}
+				checkPackage(t, c, ctx, pkg.Syntax)
 			}
 		})
 	}
 }
 
-func checkFile(t *testing.T, c *lintpack.Checker, ctx *lintpack.Context, f *ast.File) {
+// testFile holds expected warnings of a single test file.
+type testFile struct {
+	filename string
+	ws       warnings
+	matched  map[*string]struct{}
+}
+
+func checkPackage(t *testing.T, c *lintpack.Checker, ctx *lintpack.Context, files []*ast.File) {
+	testFiles := make(map[*token.File]*testFile, len(files))
+	for _, f := range files {
+		tf := newTestFile(t, c, ctx, f)
+		testFiles[ctx.FileSet.File(f.Pos())] = tf
+
+		stripDirectives(f)
+		ctx.SetFileInfo(getFilename(ctx.FileSet, f), f)
+		for _, warn := range c.Check(f) {
+			tf.match(t, ctx.FileSet, warn)
+		}
+	}
+
+	for _, warn := range c.CheckPackage(files) {
+		tf := testFiles[ctx.FileSet.File(warn.Node.Pos())]
+		if tf == nil {
+			t.Errorf("%s: warn outside of package files: %s",
+				ctx.FileSet.Position(warn.Node.Pos()), warn.Text)
+			continue
+		}
+		tf.match(t, ctx.FileSet, warn)
+	}
+
+	for _, tf := range testFiles {
+		checkUnmatched(tf.ws, tf.matched, t, tf.filename)
+	}
+}
+
+func newTestFile(t *testing.T, c *lintpack.Checker, ctx *lintpack.Context, f *ast.File) *testFile {
 	filename := getFilename(ctx.FileSet, f)
 	testFilename := filepath.Join("testdata", c.Info.Name, filename)
 
@@ -109,26 +145,26 @@ func checkFile(t *testing.T, c *lintpack.Checker, ctx *lintpack.Context, f *ast.
 		t.Fatal(err)
 	}
 
-	stripDirectives(f)
-	ctx.SetFileInfo(filename, f)
+	return &testFile{
+		filename: testFilename,
+		ws:       ws,
+		matched:  make(map[*string]struct{}),
+	}
+}
 
-	matched := make(map[*string]struct{})
-	for _, warn := range c.Check(f) {
-		line := ctx.FileSet.Position(warn.Node.Pos()).Line
+func (tf *testFile) match(t *testing.T, fset *token.FileSet, warn lintpack.Warning) {
+	line := fset.Position(warn.Node.Pos()).Line
 
-		if w := ws.find(line, warn.Text); w != nil {
-			if _, seen := matched[w]; seen {
-				t.Errorf("%s:%d: multiple matches for %s",
-					testFilename, line, *w)
-			}
-			matched[w] = struct{}{}
-		} else {
-			t.Errorf("%s:%d: unexpected warn: %s",
-				testFilename, line, warn.Text)
+	if w := tf.ws.find(line, warn.Text); w != nil {
+		if _, seen := tf.matched[w]; seen {
+			t.Errorf("%s:%d: multiple matches for %s",
+				tf.filename, line, *w)
 		}
+		tf.matched[w] = struct{}{}
+	} else {
+		t.Errorf("%s:%d: unexpected warn: %s",
+			tf.filename, line, warn.Text)
 	}
-
-	checkUnmatched(ws, matched, t, testFilename)
 }
 
 // stripDirectives replaces "///" comments with empty single-line
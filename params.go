@@ -0,0 +1,165 @@
+package lintpack
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String returns a textual representation of the param value.
+// The result can be parsed back with Set.
+func (p *CheckerParam) String() string {
+	if p == nil {
+		return "" // Zero value, see flag.isZeroValue
+	}
+	switch v := p.Value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case *regexp.Regexp:
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Set parses s according to the param value type, validates it
+// and assigns the result to the param value.
+//
+// []string values are parsed from a comma-separated list.
+// Empty string sets *regexp.Regexp value to nil.
+func (p *CheckerParam) Set(s string) error {
+	var v interface{}
+	var err error
+	switch p.Value.(type) {
+	case int:
+		v, err = strconv.Atoi(s)
+	case bool:
+		v, err = strconv.ParseBool(s)
+	case string:
+		v = s
+	case float64:
+		v, err = strconv.ParseFloat(s, 64)
+	case time.Duration:
+		v, err = time.ParseDuration(s)
+	case []string:
+		list := []string{}
+		for _, x := range strings.Split(s, ",") {
+			if x = strings.TrimSpace(x); x != "" {
+				list = append(list, x)
+			}
+		}
+		v = list
+	case *regexp.Regexp:
+		var re *regexp.Regexp
+		if s != "" {
+			re, err = regexp.Compile(s)
+		}
+		v = re
+	default:
+		return fmt.Errorf("unsupported param type: %T", p.Value)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := p.validateValue(v); err != nil {
+		return err
+	}
+	p.Value = v
+	return nil
+}
+
+// IsBoolFlag reports whether param is a boolean param.
+// Used by the flag package to permit flags without explicit value.
+func (p *CheckerParam) IsBoolFlag() bool {
+	_, ok := p.Value.(bool)
+	return ok
+}
+
+// Validate checks that param value type is permitted,
+// that constraints are consistent with the value type and
+// that the current value satisfies them.
+func (p *CheckerParam) Validate() error {
+	switch p.Value.(type) {
+	case int, float64, time.Duration:
+		for _, bound := range []interface{}{p.Min, p.Max} {
+			if bound != nil && fmt.Sprintf("%T", bound) != fmt.Sprintf("%T", p.Value) {
+				return fmt.Errorf("min/max type %T doesn't match %T value type",
+					bound, p.Value)
+			}
+		}
+		if p.Min != nil && p.Max != nil && compareNumbers(p.Min, p.Max) > 0 {
+			return errors.New("min is greater than max")
+		}
+	case string, bool, []string, *regexp.Regexp:
+		if p.Min != nil || p.Max != nil {
+			return fmt.Errorf("min/max constraints are not supported for %T", p.Value)
+		}
+	default:
+		return fmt.Errorf("unsupported param type: %T", p.Value)
+	}
+	if _, ok := p.Value.(string); !ok && len(p.Enum) != 0 {
+		return fmt.Errorf("enum constraint is not supported for %T", p.Value)
+	}
+
+	return p.validateValue(p.Value)
+}
+
+// checkValue checks that v has the param value type
+// and satisfies param constraints.
+func (p *CheckerParam) checkValue(v interface{}) error {
+	if fmt.Sprintf("%T", v) != fmt.Sprintf("%T", p.Value) {
+		return fmt.Errorf("value type %T doesn't match %T param type", v, p.Value)
+	}
+	return p.validateValue(v)
+}
+
+// validateValue checks that v satisfies param constraints.
+func (p *CheckerParam) validateValue(v interface{}) error {
+	if p.Min != nil && compareNumbers(v, p.Min) < 0 {
+		return fmt.Errorf("value %v is less than min %v", v, p.Min)
+	}
+	if p.Max != nil && compareNumbers(v, p.Max) > 0 {
+		return fmt.Errorf("value %v is greater than max %v", v, p.Max)
+	}
+	if s, ok := v.(string); ok && len(p.Enum) != 0 {
+		for _, allowed := range p.Enum {
+			if s == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q is not one of %s",
+			s, strings.Join(p.Enum, ", "))
+	}
+	return nil
+}
+
+// compareNumbers returns -1, 0 or +1 depending on whether x is
+// less, equal or greater than y. Both values must have the same type.
+func compareNumbers(x, y interface{}) int {
+	var a, b float64
+	switch x := x.(type) {
+	case int:
+		a, b = float64(x), float64(y.(int))
+	case float64:
+		a, b = x, y.(float64)
+	case time.Duration:
+		a, b = float64(x), float64(y.(time.Duration))
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return +1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,87 @@
+package lintpack
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCheckerParamSet(t *testing.T) {
+	tests := []struct {
+		param *CheckerParam
+		input string
+		want  interface{}
+	}{
+		{&CheckerParam{Value: 10}, "20", 20},
+		{&CheckerParam{Value: false}, "true", true},
+		{&CheckerParam{Value: "a"}, "b", "b"},
+		{&CheckerParam{Value: 0.5}, "1.25", 1.25},
+		{&CheckerParam{Value: time.Second}, "2m", 2 * time.Minute},
+		{&CheckerParam{Value: []string{}}, "a, b,,c", []string{"a", "b", "c"}},
+		{&CheckerParam{Value: []string{"x"}}, "", []string{}},
+		{&CheckerParam{Value: (*regexp.Regexp)(nil)}, "", (*regexp.Regexp)(nil)},
+		{&CheckerParam{Value: 1, Min: 0, Max: 5}, "5", 5},
+		{&CheckerParam{Value: "fast", Enum: []string{"fast", "slow"}}, "slow", "slow"},
+	}
+
+	for _, test := range tests {
+		if err := test.param.Validate(); err != nil {
+			t.Errorf("validate %#v: %v", test.param.Value, err)
+			continue
+		}
+		if err := test.param.Set(test.input); err != nil {
+			t.Errorf("set %q: %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.param.Value, test.want) {
+			t.Errorf("set %q: have %#v, want %#v", test.input, test.param.Value, test.want)
+		}
+	}
+
+	re := &CheckerParam{Value: regexp.MustCompile(`^a`)}
+	if err := re.Set(`b+`); err != nil {
+		t.Fatalf("set regexp: %v", err)
+	}
+	if re.String() != `b+` || !re.Value.(*regexp.Regexp).MatchString("bb") {
+		t.Errorf("regexp value is not updated: %v", re.Value)
+	}
+}
+
+func TestCheckerParamErrors(t *testing.T) {
+	invalidValues := []struct {
+		param *CheckerParam
+		input string
+	}{
+		{&CheckerParam{Value: 1}, "x"},
+		{&CheckerParam{Value: 1, Min: 0, Max: 5}, "6"},
+		{&CheckerParam{Value: 1, Min: 0, Max: 5}, "-1"},
+		{&CheckerParam{Value: time.Second, Min: time.Millisecond}, "1us"},
+		{&CheckerParam{Value: "fast", Enum: []string{"fast", "slow"}}, "medium"},
+		{&CheckerParam{Value: regexp.MustCompile(`x`)}, "("},
+	}
+	for _, test := range invalidValues {
+		before := test.param.Value
+		if err := test.param.Set(test.input); err == nil {
+			t.Errorf("set %q: expected error", test.input)
+		}
+		if !reflect.DeepEqual(test.param.Value, before) {
+			t.Errorf("set %q: value is changed on error", test.input)
+		}
+	}
+
+	invalidParams := []*CheckerParam{
+		{Value: uint(1)},
+		{Value: 1, Min: 1.5},
+		{Value: 1, Min: 5, Max: 0},
+		{Value: 10, Max: 5},
+		{Value: true, Min: false},
+		{Value: 1, Enum: []string{"1"}},
+		{Value: "a", Enum: []string{"b"}},
+	}
+	for _, param := range invalidParams {
+		if err := param.Validate(); err == nil {
+			t.Errorf("validate %#v: expected error", param)
+		}
+	}
+}
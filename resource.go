@@ -0,0 +1,184 @@
+package lintpack
+
+import (
+	"fmt"
+	"go/ast"
+	"sync"
+)
+
+// ResourceScope describes how often a resource is computed.
+type ResourceScope int
+
+// Resource scopes.
+const (
+	// FileScope resources are computed once per file.
+	FileScope ResourceScope = iota
+
+	// PackageScope resources are computed once per package.
+	PackageScope
+)
+
+// Resource describes a piece of analysis data that can be
+// shared among all checkers that use the same Context.
+//
+// Resources are computed lazily, on the first request,
+// and at most once per file or package, depending on the Scope.
+// Checkers must list all resources they use in CheckerInfo.Resources.
+//
+// Resources are identified by their addresses, so they should be
+// declared as package-level variables.
+type Resource struct {
+	// Name is a resource name, used in error messages.
+	Name string
+
+	// Scope describes whether resource is computed per file or per package.
+	Scope ResourceScope
+
+	// Compute returns a resource value.
+	// For file-scoped resources f is a file to compute the value for.
+	// For package-scoped resources f is nil.
+	//
+	// Compute can be called concurrently for different files,
+	// so it should only use f and the package-level Context fields.
+	// The returned value is shared and must not be modified.
+	Compute func(ctx *Context, f *ast.File) interface{}
+}
+
+// ParentsResource maps every file node to its parent node.
+// The value type is map[ast.Node]ast.Node.
+var ParentsResource = &Resource{
+	Name:  "parents",
+	Scope: FileScope,
+	Compute: func(ctx *Context, f *ast.File) interface{} {
+		parents := make(map[ast.Node]ast.Node)
+		var stack []ast.Node
+		ast.Inspect(f, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return false
+			}
+			if len(stack) != 0 {
+				parents[n] = stack[len(stack)-1]
+			}
+			stack = append(stack, n)
+			return true
+		})
+		return parents
+	},
+}
+
+// CommentMapResource associates file comments with the nodes.
+// The value type is ast.CommentMap.
+var CommentMapResource = &Resource{
+	Name:  "commentMap",
+	Scope: FileScope,
+	Compute: func(ctx *Context, f *ast.File) interface{} {
+		return ast.NewCommentMap(ctx.FileSet, f, f.Comments)
+	},
+}
+
+// FileResource returns r value for the file f.
+// r must be a file-scoped resource.
+func (c *Context) FileResource(r *Resource, f *ast.File) interface{} {
+	if r.Scope != FileScope {
+		panic(fmt.Sprintf("%s is not a file-scoped resource", r.Name))
+	}
+	return c.resources.get(c, r, f)
+}
+
+// PackageResource returns r value for the package being checked.
+// r must be a package-scoped resource.
+func (c *Context) PackageResource(r *Resource) interface{} {
+	if r.Scope != PackageScope {
+		panic(fmt.Sprintf("%s is not a package-scoped resource", r.Name))
+	}
+	return c.resources.get(c, r, nil)
+}
+
+// FileResource is like Context.FileResource, but also checks that
+// r is declared in the checker info.
+func (ctx *CheckerContext) FileResource(r *Resource, f *ast.File) interface{} {
+	ctx.checkResource(r)
+	return ctx.Context.FileResource(r, f)
+}
+
+// PackageResource is like Context.PackageResource, but also checks that
+// r is declared in the checker info.
+func (ctx *CheckerContext) PackageResource(r *Resource) interface{} {
+	ctx.checkResource(r)
+	return ctx.Context.PackageResource(r)
+}
+
+func (ctx *CheckerContext) checkResource(r *Resource) {
+	for _, declared := range ctx.info.Resources {
+		if declared == r {
+			return
+		}
+	}
+	panic(fmt.Sprintf("%s: %s resource is not declared in CheckerInfo.Resources",
+		ctx.info.Name, r.Name))
+}
+
+// resourceKey identifies a single resource value.
+type resourceKey struct {
+	resource *Resource
+
+	// file is nil for package-scoped resources.
+	file *ast.File
+}
+
+// resourceValue is a lazily computed resource value.
+type resourceValue struct {
+	once  sync.Once
+	value interface{}
+
+	// panicked is true if Compute panicked, panicValue is its argument.
+	// The panic is repeated on every access, since there is no value.
+	panicked   bool
+	panicValue interface{}
+}
+
+// resourceCache holds resource values computed for the current package.
+// Safe for concurrent use; the zero value is ready to use.
+type resourceCache struct {
+	mu     sync.Mutex
+	values map[resourceKey]*resourceValue
+}
+
+func (cache *resourceCache) get(ctx *Context, r *Resource, f *ast.File) interface{} {
+	key := resourceKey{resource: r, file: f}
+
+	cache.mu.Lock()
+	if cache.values == nil {
+		cache.values = make(map[resourceKey]*resourceValue)
+	}
+	v := cache.values[key]
+	if v == nil {
+		v = &resourceValue{}
+		cache.values[key] = v
+	}
+	cache.mu.Unlock()
+
+	// Computation is done outside of the cache lock, so
+	// unrelated resources can be computed concurrently.
+	v.once.Do(func() {
+		v.panicked = true
+		defer func() {
+			if v.panicked {
+				v.panicValue = recover()
+			}
+		}()
+		v.value = r.Compute(ctx, f)
+		v.panicked = false
+	})
+	if v.panicked {
+		panic(v.panicValue)
+	}
+	return v.value
+}
+
+func (cache *resourceCache) reset() {
+	cache.mu.Lock()
+	cache.values = nil
+	cache.mu.Unlock()
+}
@@ -0,0 +1,138 @@
+package lintpack
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResources(t *testing.T) {
+	var computed int32
+	funcCount := &Resource{
+		Name:  "funcCount",
+		Scope: PackageScope,
+		Compute: func(ctx *Context, f *ast.File) interface{} {
+			atomic.AddInt32(&computed, 1)
+			n := 0
+			for _, f := range ctx.Files {
+				for _, decl := range f.Decls {
+					if _, ok := decl.(*ast.FuncDecl); ok {
+						n++
+					}
+				}
+			}
+			return n
+		},
+	}
+
+	coll := &CheckerCollection{URL: "example.com", Registry: NewRegistry()}
+	var infoList []*CheckerInfo
+	for _, name := range []string{"a", "b"} {
+		info := &CheckerInfo{
+			Name:      name,
+			Summary:   "Reports function comments",
+			Resources: []*Resource{funcCount, CommentMapResource, ParentsResource},
+		}
+		coll.AddChecker(info, func(ctx *CheckerContext) FileWalker {
+			return &resourceWalker{ctx: ctx, funcCount: funcCount}
+		})
+		infoList = append(infoList, info)
+	}
+	undeclared := &CheckerInfo{Name: "undeclared", Summary: "Uses undeclared resource"}
+	coll.AddChecker(undeclared, func(ctx *CheckerContext) FileWalker {
+		return &resourceWalker{ctx: ctx, funcCount: funcCount}
+	})
+
+	fset := token.NewFileSet()
+	src := "package example\n\n// f is documented.\nfunc f() {}\n\nfunc g() {}\n"
+	f, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	typesInfo := &types.Info{}
+	pkg, err := new(types.Config).Check("example", fset, []*ast.File{f}, typesInfo)
+	if err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+
+	ctx := NewContext(fset, types.SizesFor("gc", "amd64"))
+	for i := 0; i < 2; i++ {
+		atomic.StoreInt32(&computed, 0)
+		ctx.SetPackageInfo(typesInfo, pkg)
+		ctx.SetPackageFiles([]*ast.File{f})
+		ctx.SetFileInfo("a.go", f)
+		for _, info := range infoList {
+			c := coll.Registry.NewChecker(ctx, info)
+			var have []string
+			for _, warn := range c.Check(f) {
+				have = append(have, warn.Text)
+			}
+			want := "f of 2 funcs is documented"
+			if len(have) != 1 || have[0] != want {
+				t.Errorf("%s: have %q, want [%q]", info.Name, have, want)
+			}
+		}
+		if computed != 1 {
+			t.Errorf("package %d: resource computed %d times, want 1", i, computed)
+		}
+	}
+
+	c := coll.Registry.NewChecker(ctx, undeclared)
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil || !strings.Contains(r.(string), "not declared") {
+				t.Errorf("expected undeclared resource panic, got %v", r)
+			}
+		}()
+		c.Check(f)
+	}()
+}
+
+type resourceWalker struct {
+	ctx       *CheckerContext
+	funcCount *Resource
+}
+
+func (w *resourceWalker) WalkFile(f *ast.File) {
+	n := w.ctx.PackageResource(w.funcCount).(int)
+	cmap := w.ctx.FileResource(CommentMapResource, f).(ast.CommentMap)
+	parents := w.ctx.FileResource(ParentsResource, f).(map[ast.Node]ast.Node)
+	for node := range cmap {
+		fn, ok := node.(*ast.FuncDecl)
+		if ok && parents[fn] == f {
+			w.ctx.Warn(fn, "%s of %d funcs is documented", fn.Name, n)
+		}
+	}
+}
+
+func TestResourcePanic(t *testing.T) {
+	var computed int32
+	broken := &Resource{
+		Name:  "broken",
+		Scope: PackageScope,
+		Compute: func(ctx *Context, f *ast.File) interface{} {
+			atomic.AddInt32(&computed, 1)
+			panic("broken resource")
+		},
+	}
+
+	ctx := NewContext(token.NewFileSet(), types.SizesFor("gc", "amd64"))
+	for i := 0; i < 3; i++ {
+		func() {
+			defer func() {
+				if r := recover(); r != "broken resource" {
+					t.Errorf("access %d: have %v panic, want the Compute panic", i, r)
+				}
+			}()
+			ctx.PackageResource(broken)
+		}()
+	}
+	if computed != 1 {
+		t.Errorf("resource computed %d times, want 1", computed)
+	}
+}
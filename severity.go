@@ -0,0 +1,48 @@
+package lintpack
+
+import (
+	"fmt"
+)
+
+// Severity describes how serious the issue reported by a checker is.
+type Severity int
+
+// Severity levels, ordered from the least to the most serious one.
+const (
+	SeverityHint Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = map[Severity]string{
+	SeverityHint:    "hint",
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+// String returns a lower-case severity name, like "warning".
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// IsValid reports whether s is one of the known severity levels.
+func (s Severity) IsValid() bool {
+	_, ok := severityNames[s]
+	return ok
+}
+
+// ParseSeverity returns a severity level by its name.
+// Valid names are "hint", "info", "warning" and "error".
+func ParseSeverity(name string) (Severity, error) {
+	for s, sname := range severityNames {
+		if sname == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", name)
+}
@@ -0,0 +1,48 @@
+package lintpack
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+)
+
+type severityWalker struct {
+	ctx      *CheckerContext
+	severity Severity
+}
+
+func (w *severityWalker) WalkFile(f *ast.File) {
+	w.ctx.WarnSeverity(w.severity, f, "warning")
+}
+
+func TestWarnSeverity(t *testing.T) {
+	coll := &CheckerCollection{URL: "example.com", Registry: NewRegistry()}
+	info := &CheckerInfo{Name: "severity", Summary: "Reports every file"}
+	walker := &severityWalker{}
+	coll.AddChecker(info, func(ctx *CheckerContext) FileWalker {
+		walker.ctx = ctx
+		return walker
+	})
+	c := coll.Registry.NewChecker(NewContext(token.NewFileSet(), nil), info)
+	f := &ast.File{Name: ast.NewIdent("example")}
+
+	for _, s := range []Severity{SeverityHint, SeverityError} {
+		walker.severity = s
+		warns := c.Check(f)
+		if len(warns) != 1 || warns[0].Severity != s {
+			t.Errorf("%v: unexpected warnings: %+v", s, warns)
+		}
+	}
+
+	for _, s := range []Severity{0, SeverityError + 1, -1} {
+		walker.severity = s
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v: expected a panic", s)
+				}
+			}()
+			c.Check(f)
+		}()
+	}
+}
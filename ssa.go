@@ -0,0 +1,99 @@
+package lintpack
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// SSA holds SSA form of the package being checked.
+type SSA struct {
+	// Pkg is an SSA package built from the package source files.
+	// Imported packages are created without function bodies.
+	Pkg *ssa.Package
+
+	// SrcFuncs lists all non-blank source functions of the package,
+	// including function literals, in source order.
+	SrcFuncs []*ssa.Function
+
+	funcs map[*ast.FuncDecl]*ssa.Function
+}
+
+// Program returns an SSA program the package belongs to.
+func (s *SSA) Program() *ssa.Program { return s.Pkg.Prog }
+
+// Func returns SSA function for the declaration.
+// Returns nil for blank functions and declarations
+// that don't belong to the package.
+func (s *SSA) Func(decl *ast.FuncDecl) *ssa.Function { return s.funcs[decl] }
+
+// SSAResource is an SSA form of the package being checked.
+// The value type is *SSA.
+//
+// SSA requires complete types info, with Types, Defs, Uses,
+// Implicits, Selections and Scopes maps populated.
+// Checkers usually get it with CheckerContext.SSA method.
+var SSAResource = &Resource{
+	Name:    "ssa",
+	Scope:   PackageScope,
+	Compute: func(ctx *Context, _ *ast.File) interface{} { return buildSSA(ctx) },
+}
+
+// SSA returns SSA form of the package being checked.
+// SSAResource must be declared in the checker info.
+func (ctx *CheckerContext) SSA() *SSA {
+	return ctx.PackageResource(SSAResource).(*SSA)
+}
+
+func buildSSA(ctx *Context) *SSA {
+	prog := ssa.NewProgram(ctx.FileSet, 0)
+
+	// Create SSA packages for all imports.
+	// Order is not significant.
+	created := make(map[*types.Package]bool)
+	var createAll func(pkgs []*types.Package)
+	createAll = func(pkgs []*types.Package) {
+		for _, p := range pkgs {
+			if !created[p] {
+				created[p] = true
+				prog.CreatePackage(p, nil, nil, true)
+				createAll(p.Imports())
+			}
+		}
+	}
+	createAll(ctx.Pkg.Imports())
+
+	pkg := prog.CreatePackage(ctx.Pkg, ctx.Files, ctx.TypesInfo, false)
+	pkg.Build()
+
+	s := &SSA{
+		Pkg:   pkg,
+		funcs: make(map[*ast.FuncDecl]*ssa.Function),
+	}
+	var addAnons func(fn *ssa.Function)
+	addAnons = func(fn *ssa.Function) {
+		s.SrcFuncs = append(s.SrcFuncs, fn)
+		for _, anon := range fn.AnonFuncs {
+			addAnons(anon)
+		}
+	}
+	for _, f := range ctx.Files {
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Name.Name == "_" {
+				// SSA functions are not built for blank funcs.
+				continue
+			}
+			obj, ok := ctx.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			if fn := prog.FuncValue(obj); fn != nil {
+				s.funcs[decl] = fn
+				addAnons(fn)
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+package lintpack
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestSSA(t *testing.T) {
+	coll := &CheckerCollection{URL: "example.com", Registry: NewRegistry()}
+	info := &CheckerInfo{
+		Name:      "ssaFuncs",
+		Summary:   "Reports SSA functions",
+		Resources: []*Resource{SSAResource},
+	}
+	coll.AddChecker(info, func(ctx *CheckerContext) FileWalker {
+		return &ssaWalker{ctx: ctx}
+	})
+
+	src := `package example
+
+import "strings"
+
+func f(s string) func() string {
+	return func() string { return strings.ToUpper(s) }
+}
+
+type T struct{}
+
+func (T) m() {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	typesInfo := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	cfg := types.Config{Importer: importer.Default()}
+	pkg, err := cfg.Check("example", fset, []*ast.File{f}, typesInfo)
+	if err != nil {
+		t.Fatalf("typecheck: %v", err)
+	}
+
+	ctx := NewContext(fset, types.SizesFor("gc", "amd64"))
+	ctx.SetPackageInfo(typesInfo, pkg)
+	ctx.SetPackageFiles([]*ast.File{f})
+	ctx.SetFileInfo("a.go", f)
+	c := coll.Registry.NewChecker(ctx, info)
+
+	var have []string
+	for _, warn := range c.Check(f) {
+		have = append(have, warn.Text)
+	}
+	want := []string{"f: 1 anon funcs", "m: 0 anon funcs", "3 src funcs"}
+	if len(have) != len(want) {
+		t.Fatalf("warnings mismatch:\nhave: %q\nwant: %q", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("warning %d: have %q, want %q", i, have[i], want[i])
+		}
+	}
+}
+
+type ssaWalker struct {
+	ctx *CheckerContext
+}
+
+func (w *ssaWalker) WalkFile(f *ast.File) {
+	s := w.ctx.SSA()
+	for _, decl := range f.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok {
+			fn := s.Func(decl)
+			w.ctx.Warn(decl, "%s: %d anon funcs", fn.Name(), len(fn.AnonFuncs))
+		}
+	}
+	w.ctx.Warn(f, "%d src funcs", len(s.SrcFuncs))
+}